@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+)
+
+// AccessTuple is one EIP-2930 access list entry as the node reports it.
+type AccessTuple struct {
+	Address     ethgo.Address `json:"address"`
+	StorageKeys []ethgo.Hash  `json:"storageKeys"`
+}
+
+// AccessListResult is the eth_createAccessList result.
+type AccessListResult struct {
+	AccessList []AccessTuple
+	// GasUsed is the gas the call used with AccessList applied.
+	GasUsed uint64
+}
+
+// CreateAccessList returns the accounts and slots msg touches. Nodes
+// without eth_createAccessList return an error matching ErrMethodNotSupported.
+func (c *Client) CreateAccessList(msg *CallMsg, block ethgo.BlockNumber) (*AccessListResult, error) {
+	return c.CreateAccessListContext(context.Background(), msg, block)
+}
+
+// CreateAccessListContext is CreateAccessList with a context.
+func (c *Client) CreateAccessListContext(ctx context.Context, msg *CallMsg, block ethgo.BlockNumber) (*AccessListResult, error) {
+	var out struct {
+		AccessList []AccessTuple `json:"accessList"`
+		GasUsed    string        `json:"gasUsed"`
+		Error      string        `json:"error"`
+	}
+	if err := c.RawCallContext(ctx, "eth_createAccessList", &out, msg, block.String()); err != nil {
+		return nil, err
+	}
+	if out.Error != "" {
+		// The call itself failed (e.g. reverted); the list may be partial.
+		return nil, wrapError(errors.New(out.Error))
+	}
+	gas, err := utils.StrToU64(out.GasUsed)
+	if err != nil {
+		return nil, err
+	}
+	return &AccessListResult{AccessList: out.AccessList, GasUsed: gas}, nil
+}
+
+// EstimateGasWithAccessList is EstimateGas for msg carrying list.
+func (c *Client) EstimateGasWithAccessList(msg *CallMsg, list []AccessTuple) (uint64, error) {
+	return c.EstimateGasWithAccessListContext(context.Background(), msg, list)
+}
+
+// EstimateGasWithAccessListContext is EstimateGasWithAccessList with a context.
+func (c *Client) EstimateGasWithAccessListContext(ctx context.Context, msg *CallMsg, list []AccessTuple) (uint64, error) {
+	params, err := callParams(msg)
+	if err != nil {
+		return 0, err
+	}
+	if list == nil {
+		list = []AccessTuple{}
+	}
+	params["accessList"] = list
+	var out string
+	if err := c.RawCallContext(ctx, "eth_estimateGas", &out, params); err != nil {
+		return 0, err
+	}
+	return utils.StrToU64(out)
+}
+
+// callParams turns msg into a JSON object that extra fields can be added to.
+func callParams(msg *CallMsg) (map[string]any, error) {
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]any{}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
@@ -0,0 +1,305 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+// DefaultBatchSize is the number of calls per JSON-RPC batch request.
+// Most providers accept 100; some (e.g. Alchemy free tier) need less.
+const DefaultBatchSize = 100
+
+var ErrBatchNotSent = errors.New("batch not sent")
+
+// Batch queues calls and sends them as JSON-RPC batch requests.
+//
+//	b := c.NewBatch()
+//	bal := b.BalanceAt(addr, ethgo.Latest)
+//	nonce := b.NonceAt(addr, ethgo.Latest)
+//	if err := b.Send(); err != nil { ... }
+//	v, err := bal.Result()
+//
+// WS/IPC endpoints have no batch support in the underlying transport; their
+// calls are sent one by one.
+type Batch struct {
+	c     *Client
+	elems []*batchElem
+
+	// MaxSize is the number of calls per request (0 -> DefaultBatchSize).
+	MaxSize int
+}
+
+type batchElem struct {
+	method string
+	params []any
+
+	done bool
+	raw  json.RawMessage
+	err  error
+}
+
+// BatchCall is the pending result of one queued call.
+type BatchCall[T any] struct {
+	elem  *batchElem
+	parse func(json.RawMessage) (T, error)
+}
+
+// Result returns the call's decoded result or its own error.
+func (r *BatchCall[T]) Result() (T, error) {
+	var zero T
+	switch {
+	case r.elem.err != nil:
+		return zero, r.elem.err
+	case !r.elem.done:
+		return zero, ErrBatchNotSent
+	}
+	return r.parse(r.elem.raw)
+}
+
+// NewBatch returns an empty batch bound to c.
+func (c *Client) NewBatch() *Batch {
+	return &Batch{c: c}
+}
+
+// Len returns the number of queued calls.
+func (b *Batch) Len() int { return len(b.elems) }
+
+func queue[T any](b *Batch, parse func(json.RawMessage) (T, error), method string, params ...any) *BatchCall[T] {
+	e := &batchElem{method: method, params: params}
+	b.elems = append(b.elems, e)
+	return &BatchCall[T]{elem: e, parse: parse}
+}
+
+/* ---------- Queued calls ---------- */
+
+// BalanceAt queues eth_getBalance.
+func (b *Batch) BalanceAt(addr string, block ethgo.BlockNumberOrHash) *BatchCall[*big.Int] {
+	return queue(b, parseBig, "eth_getBalance", ethgo.HexToAddress(addr), location(block))
+}
+
+// NonceAt queues eth_getTransactionCount.
+func (b *Batch) NonceAt(addr string, block ethgo.BlockNumberOrHash) *BatchCall[uint64] {
+	return queue(b, parseU64, "eth_getTransactionCount", ethgo.HexToAddress(addr), location(block))
+}
+
+// CodeAt queues eth_getCode.
+func (b *Batch) CodeAt(addr string, block ethgo.BlockNumberOrHash) *BatchCall[[]byte] {
+	return queue(b, parseBytes, "eth_getCode", ethgo.HexToAddress(addr), location(block))
+}
+
+// Call queues eth_call; the result is the returned data as hex, like Client.Call.
+func (b *Batch) Call(msg *CallMsg, block ethgo.BlockNumber) *BatchCall[string] {
+	return queue(b, parseJSON[string], "eth_call", msg, block.String())
+}
+
+// TransactionReceipt queues eth_getTransactionReceipt (nil result before mined).
+func (b *Batch) TransactionReceipt(h ethgo.Hash) *BatchCall[*ethgo.Receipt] {
+	return queue(b, parseJSON[*ethgo.Receipt], "eth_getTransactionReceipt", h)
+}
+
+// BlockByNumber queues eth_getBlockByNumber.
+func (b *Batch) BlockByNumber(n ethgo.BlockNumber, full bool) *BatchCall[*ethgo.Block] {
+	return queue(b, parseJSON[*ethgo.Block], "eth_getBlockByNumber", n.String(), full)
+}
+
+// Raw queues any method; decode the result with json.Unmarshal.
+func (b *Batch) Raw(method string, params ...any) *BatchCall[json.RawMessage] {
+	return queue(b, parseJSON[json.RawMessage], method, params...)
+}
+
+func parseJSON[T any](raw json.RawMessage) (T, error) {
+	var out T
+	err := json.Unmarshal(raw, &out)
+	return out, err
+}
+
+func parseBig(raw json.RawMessage) (*big.Int, error) {
+	s, err := parseJSON[string](raw)
+	if err != nil {
+		return nil, err
+	}
+	return utils.StrToBig(s)
+}
+
+func parseU64(raw json.RawMessage) (uint64, error) {
+	s, err := parseJSON[string](raw)
+	if err != nil {
+		return 0, err
+	}
+	return utils.StrToU64(s)
+}
+
+func parseBytes(raw json.RawMessage) ([]byte, error) {
+	s, err := parseJSON[string](raw)
+	if err != nil {
+		return nil, err
+	}
+	return utils.FromHex(s)
+}
+
+/* ---------- Sending ---------- */
+
+// Send sends every queued call that has not been sent yet.
+func (b *Batch) Send() error {
+	return b.SendContext(context.Background())
+}
+
+// SendContext sends every queued call that has not been sent yet, in chunks
+// of MaxSize. Per-call errors are reported by BatchCall.Result; the returned
+// error is the first failure of a whole request (transport, rate limit,
+// oversized batch), which is also recorded on the unanswered calls of that
+// chunk. Calls that failed are not resent by a later Send.
+// Client.Timeout applies per chunk.
+func (b *Batch) SendContext(ctx context.Context) error {
+	size := b.MaxSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	var pending []*batchElem
+	for _, e := range b.elems {
+		if !e.done && e.err == nil {
+			pending = append(pending, e)
+		}
+	}
+
+	var first error
+	for i := 0; i < len(pending); i += size {
+		chunk := pending[i:min(i+size, len(pending))]
+		err := ctx.Err()
+		if err == nil {
+			err = b.c.sendBatch(ctx, chunk)
+		}
+		if err != nil {
+			for _, e := range chunk {
+				if !e.done && e.err == nil {
+					e.err = err
+				}
+			}
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+func (c *Client) sendBatch(ctx context.Context, elems []*batchElem) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if c.Retry == nil {
+		return c.batchAttempt(ctx, elems)
+	}
+	// A batch is only as retry-safe as its least safe call.
+	method := ""
+	for _, e := range elems {
+		if nonIdempotent[e.method] {
+			method = e.method
+		}
+	}
+	return c.Retry.retry(ctx, method, func() error {
+		return c.batchAttempt(ctx, elems)
+	})
+}
+
+func (c *Client) batchAttempt(ctx context.Context, elems []*batchElem) error {
+	for _, e := range elems {
+		e.done, e.raw, e.err = false, nil, nil
+	}
+	switch {
+	case c.pool != nil:
+		return c.pool.batch(ctx, elems)
+	case c.http != nil:
+		return wrapError(c.http.batch(ctx, elems))
+	}
+	for _, e := range elems {
+		e.err = c.attempt(ctx, e.method, &e.raw, e.params...)
+		e.done = e.err == nil
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (t *httpTransport) batch(ctx context.Context, elems []*batchElem) error {
+	reqs := make([]codec.Request, len(elems))
+	byID := make(map[uint64]*batchElem, len(elems))
+	for i, e := range elems {
+		reqs[i] = codec.Request{JsonRPC: "2.0", ID: t.id.Add(1), Method: e.method}
+		if len(e.params) > 0 {
+			p, err := json.Marshal(e.params)
+			if err != nil {
+				return err
+			}
+			reqs[i].Params = p
+		}
+		byID[reqs[i].ID] = e
+	}
+	body, err := json.Marshal(reqs)
+	if err != nil {
+		return err
+	}
+	data, err := t.post(ctx, body)
+	if err != nil {
+		return err
+	}
+
+	// Providers reject a whole batch (too large, rate limited) with a single
+	// error object instead of an array.
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '{' {
+		var res codec.Response
+		if err := json.Unmarshal(data, &res); err != nil {
+			return err
+		}
+		if res.Error != nil {
+			return res.Error
+		}
+		return fmt.Errorf("unexpected batch response: %s", data)
+	}
+
+	var res []codec.Response
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	for _, r := range res {
+		e := byID[r.ID]
+		if e == nil {
+			continue
+		}
+		if r.Error != nil {
+			e.err = wrapError(r.Error)
+		} else {
+			e.raw, e.done = r.Result, true
+		}
+		delete(byID, r.ID)
+	}
+	for _, e := range byID {
+		e.err = errors.New("no response for call in batch")
+	}
+	return nil
+}
+
+func (p *pool) batch(ctx context.Context, elems []*batchElem) error {
+	var errs []error
+	for _, n := range p.candidates() {
+		err := n.c.batchAttempt(ctx, elems)
+		if err == nil || !failover(ctx, err) {
+			return err
+		}
+		p.markFailed(n, err)
+		errs = append(errs, fmt.Errorf("%s: %w", n.endpoint, err))
+	}
+	if len(errs) == 0 {
+		return ErrNoEndpoints
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+func TestBatch(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var reqs []codec.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+		res := make([]codec.Response, len(reqs))
+		// Answer in reverse order; clients must match by id.
+		for i, req := range reqs {
+			out := &res[len(reqs)-1-i]
+			out.ID = req.ID
+			switch req.Method {
+			case "eth_getBalance":
+				out.Result = json.RawMessage(`"0x2a"`)
+			case "eth_getTransactionCount":
+				out.Result = json.RawMessage(`"0x3"`)
+			default:
+				out.Error = &codec.ErrorObject{Code: -32601, Message: "method not found"}
+			}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	defer srv.Close()
+
+	cli, err := dial(srv.URL)
+	require.NoError(t, err)
+
+	b := cli.NewBatch()
+	b.MaxSize = 2
+	bal := b.BalanceAt("0x0000000000000000000000000000000000000001", ethgo.Latest)
+	nonce := b.NonceAt("0x0000000000000000000000000000000000000001", ethgo.Latest)
+	raw := b.Raw("eth_unknown")
+
+	_, err = bal.Result()
+	require.ErrorIs(t, err, ErrBatchNotSent)
+
+	require.NoError(t, b.Send())
+	require.Equal(t, int32(2), requests.Load())
+
+	v, err := bal.Result()
+	require.NoError(t, err)
+	require.Equal(t, int64(42), v.Int64())
+
+	n, err := nonce.Result()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), n)
+
+	_, err = raw.Result()
+	var obj *codec.ErrorObject
+	require.ErrorAs(t, err, &obj)
+	require.Equal(t, -32601, obj.Code)
+}
@@ -0,0 +1,292 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+)
+
+var ErrNoBlobGas = errors.New("block has no blob gas fields (pre-Cancun)")
+
+const (
+	// BlobGasPerBlob is the blob gas one blob consumes (EIP-4844).
+	BlobGasPerBlob = 1 << 17
+	// MinBlobBaseFee is the floor of the blob base fee, in wei.
+	MinBlobBaseFee = 1
+	// BlobBaseCost is the execution-gas reserve price of a blob (EIP-7918).
+	BlobBaseCost = 1 << 13
+
+	slotSeconds = 12
+)
+
+// BlobParams are the blob limits and pricing of one fork.
+type BlobParams struct {
+	Target         uint64 // target blobs per block
+	Max            uint64 // max blobs per block
+	MaxPerTx       uint64 // max blobs per transaction (0 -> Max)
+	UpdateFraction uint64 // blob base fee update fraction
+	// ReservePrice enables the EIP-7918 execution-cost floor (Osaka and later).
+	ReservePrice bool
+}
+
+// TargetBlobGas returns the per-block blob gas target.
+func (p BlobParams) TargetBlobGas() uint64 { return p.Target * BlobGasPerBlob }
+
+// MaxBlobGas returns the per-block blob gas limit.
+func (p BlobParams) MaxBlobGas() uint64 { return p.Max * BlobGasPerBlob }
+
+// TxBlobLimit returns the most blobs a single transaction may carry.
+func (p BlobParams) TxBlobLimit() uint64 {
+	if p.MaxPerTx != 0 {
+		return p.MaxPerTx
+	}
+	return p.Max
+}
+
+// BlobFork is a BlobParams change activated at a block timestamp.
+type BlobFork struct {
+	Name string
+	Time uint64
+	BlobParams
+}
+
+// BlobSchedule lists a chain's blob forks in activation order.
+type BlobSchedule []BlobFork
+
+var (
+	cancunBlobs = BlobParams{Target: 3, Max: 6, UpdateFraction: 3338477}
+	pragueBlobs = BlobParams{Target: 6, Max: 9, UpdateFraction: 5007716}
+	osakaBlobs  = BlobParams{Target: 6, Max: 9, MaxPerTx: 6, UpdateFraction: 5007716, ReservePrice: true}
+	bpo1Blobs   = BlobParams{Target: 10, Max: 15, MaxPerTx: 6, UpdateFraction: 8346193, ReservePrice: true}
+	bpo2Blobs   = BlobParams{Target: 14, Max: 21, MaxPerTx: 6, UpdateFraction: 11684671, ReservePrice: true}
+
+	MainnetBlobSchedule = BlobSchedule{
+		{"cancun", 1710338135, cancunBlobs},
+		{"prague", 1746612311, pragueBlobs},
+		{"osaka", 1764798551, osakaBlobs},
+		{"bpo1", 1765290071, bpo1Blobs},
+		{"bpo2", 1767747671, bpo2Blobs},
+	}
+	SepoliaBlobSchedule = BlobSchedule{
+		{"cancun", 1706655072, cancunBlobs},
+		{"prague", 1741159776, pragueBlobs},
+		{"osaka", 1760427360, osakaBlobs},
+		{"bpo1", 1761017184, bpo1Blobs},
+		{"bpo2", 1761607008, bpo2Blobs},
+	}
+)
+
+// BlobScheduleFor returns the blob schedule of a chain. Unknown chains get
+// the mainnet schedule.
+func BlobScheduleFor(chainID *big.Int) BlobSchedule {
+	if chainID != nil && chainID.Cmp(big.NewInt(11155111)) == 0 {
+		return SepoliaBlobSchedule
+	}
+	return MainnetBlobSchedule
+}
+
+// At returns the params active at timestamp, or false before the first fork.
+func (s BlobSchedule) At(timestamp uint64) (BlobFork, bool) {
+	for i := len(s) - 1; i >= 0; i-- {
+		if timestamp >= s[i].Time {
+			return s[i], true
+		}
+	}
+	return BlobFork{}, false
+}
+
+/* ---------- Blob gas arithmetic ---------- */
+
+// CalcBlobBaseFee returns the blob base fee for a block's excess blob gas.
+func CalcBlobBaseFee(p BlobParams, excessBlobGas uint64) *big.Int {
+	return fakeExponential(big.NewInt(MinBlobBaseFee), new(big.Int).SetUint64(excessBlobGas), new(big.Int).SetUint64(p.UpdateFraction))
+}
+
+// CalcExcessBlobGas returns a block's excess blob gas from its parent's
+// excess blob gas, blob gas used and base fee. p are the child's params and
+// parent the parent's; they differ when the child is the first block of a
+// fork, and the EIP-7918 reserve price compares against the parent's blob
+// base fee under the parent's params.
+func CalcExcessBlobGas(p, parent BlobParams, parentExcess, parentUsed uint64, parentBaseFee *big.Int) uint64 {
+	target := p.TargetBlobGas()
+	if parentExcess+parentUsed < target {
+		return 0
+	}
+	if p.ReservePrice && parentBaseFee != nil {
+		// Execution cost dominates: grow excess without the target discount.
+		reserve := new(big.Int).Mul(big.NewInt(BlobBaseCost), parentBaseFee)
+		blob := new(big.Int).Mul(big.NewInt(BlobGasPerBlob), CalcBlobBaseFee(parent, parentExcess))
+		if reserve.Cmp(blob) > 0 {
+			return parentExcess + parentUsed*(p.Max-p.Target)/p.Max
+		}
+	}
+	return parentExcess + parentUsed - target
+}
+
+// fakeExponential approximates factor * e^(numerator/denominator).
+func fakeExponential(factor, numerator, denominator *big.Int) *big.Int {
+	output := new(big.Int)
+	accum := new(big.Int).Mul(factor, denominator)
+	for i := int64(1); accum.Sign() > 0; i++ {
+		output.Add(output, accum)
+		accum.Mul(accum, numerator)
+		accum.Div(accum, new(big.Int).Mul(denominator, big.NewInt(i)))
+	}
+	return output.Div(output, denominator)
+}
+
+/* ---------- Client methods ---------- */
+
+// BlobGasInfo holds the blob fields of a block header.
+type BlobGasInfo struct {
+	Number        uint64
+	Timestamp     uint64
+	BaseFee       *big.Int
+	BlobGasUsed   uint64
+	ExcessBlobGas uint64
+}
+
+// BlobBaseFee returns the blob base fee of the next block (eth_blobBaseFee).
+func (c *Client) BlobBaseFee() (*big.Int, error) {
+	return c.BlobBaseFeeContext(context.Background())
+}
+
+// BlobBaseFeeContext is BlobBaseFee with a context.
+func (c *Client) BlobBaseFeeContext(ctx context.Context) (*big.Int, error) {
+	var out string
+	if err := c.RawCallContext(ctx, "eth_blobBaseFee", &out); err != nil {
+		return nil, err
+	}
+	return utils.StrToBig(out)
+}
+
+// BlobGasAt reads the blob gas fields of a block header. It returns
+// ErrNoBlobGas for blocks before Cancun.
+func (c *Client) BlobGasAt(block ethgo.BlockNumber) (*BlobGasInfo, error) {
+	return c.BlobGasAtContext(context.Background(), block)
+}
+
+// BlobGasAtContext is BlobGasAt with a context.
+func (c *Client) BlobGasAtContext(ctx context.Context, block ethgo.BlockNumber) (*BlobGasInfo, error) {
+	var h *struct {
+		Number        string  `json:"number"`
+		Timestamp     string  `json:"timestamp"`
+		BaseFee       *string `json:"baseFeePerGas"`
+		BlobGasUsed   *string `json:"blobGasUsed"`
+		ExcessBlobGas *string `json:"excessBlobGas"`
+	}
+	if err := c.RawCallContext(ctx, "eth_getBlockByNumber", &h, block.String(), false); err != nil {
+		return nil, err
+	}
+	if h == nil {
+		return nil, errors.New("block not found")
+	}
+	if h.BlobGasUsed == nil || h.ExcessBlobGas == nil {
+		return nil, ErrNoBlobGas
+	}
+
+	var info BlobGasInfo
+	var err error
+	if info.Number, err = utils.StrToU64(h.Number); err != nil {
+		return nil, err
+	}
+	if info.Timestamp, err = utils.StrToU64(h.Timestamp); err != nil {
+		return nil, err
+	}
+	if h.BaseFee != nil {
+		if info.BaseFee, err = utils.StrToBig(*h.BaseFee); err != nil {
+			return nil, err
+		}
+	}
+	if info.BlobGasUsed, err = utils.StrToU64(*h.BlobGasUsed); err != nil {
+		return nil, err
+	}
+	if info.ExcessBlobGas, err = utils.StrToU64(*h.ExcessBlobGas); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+/* ---------- Blob fee suggestion ---------- */
+
+// BlobFeeSuggestion is a blob fee quote.
+type BlobFeeSuggestion struct {
+	// BlobBaseFee is the next block's blob base fee.
+	BlobBaseFee *big.Int
+	// MaxFeePerBlobGas covers the blob base fee for Blocks blocks even if
+	// every one of them is full.
+	MaxFeePerBlobGas *big.Int
+	Blocks           uint64
+	// Fork and Params apply to the next block.
+	Fork   string
+	Params BlobParams
+}
+
+// SuggestBlobFee projects the blob base fee blocks ahead (0 -> 1) from the
+// latest header, assuming full blocks, and returns it as MaxFeePerBlobGas.
+// Fork changes inside the window are taken into account.
+func (c *Client) SuggestBlobFee(blocks uint64) (*BlobFeeSuggestion, error) {
+	return c.SuggestBlobFeeContext(context.Background(), blocks)
+}
+
+// SuggestBlobFeeContext is SuggestBlobFee with a context.
+func (c *Client) SuggestBlobFeeContext(ctx context.Context, blocks uint64) (*BlobFeeSuggestion, error) {
+	if blocks == 0 {
+		blocks = 1
+	}
+	head, err := c.BlobGasAtContext(ctx, ethgo.Latest)
+	if err != nil {
+		return nil, err
+	}
+	schedule := BlobScheduleFor(c.ChainId)
+	return projectBlobFee(schedule, head, blocks, c.nodeBlobBaseFee(ctx))
+}
+
+// nodeBlobBaseFee returns eth_blobBaseFee, or nil if the node lacks it.
+func (c *Client) nodeBlobBaseFee(ctx context.Context) *big.Int {
+	fee, err := c.BlobBaseFeeContext(ctx)
+	if err != nil {
+		return nil
+	}
+	return fee
+}
+
+// projectBlobFee walks blocks forward from head. nodeFee, when set, floors
+// the next block's fee (the node knows its own fork schedule best).
+func projectBlobFee(schedule BlobSchedule, head *BlobGasInfo, blocks uint64, nodeFee *big.Int) (*BlobFeeSuggestion, error) {
+	next, ok := schedule.At(head.Timestamp + slotSeconds)
+	if !ok {
+		return nil, ErrNoBlobGas
+	}
+	out := &BlobFeeSuggestion{Blocks: blocks, Fork: next.Name, Params: next.BlobParams}
+
+	parent, ok := schedule.At(head.Timestamp)
+	if !ok {
+		parent = next
+	}
+	excess, used := head.ExcessBlobGas, head.BlobGasUsed
+	for i := uint64(1); i <= blocks; i++ {
+		fork, _ := schedule.At(head.Timestamp + i*slotSeconds)
+		// Base fee is held at the head's; only the blob side is projected.
+		excess = CalcExcessBlobGas(fork.BlobParams, parent.BlobParams, excess, used, head.BaseFee)
+		fee := CalcBlobBaseFee(fork.BlobParams, excess)
+		if i == 1 {
+			if nodeFee != nil && nodeFee.Cmp(fee) > 0 {
+				fee = nodeFee
+			}
+			out.BlobBaseFee = fee
+		}
+		if out.MaxFeePerBlobGas == nil || fee.Cmp(out.MaxFeePerBlobGas) > 0 {
+			out.MaxFeePerBlobGas = fee
+		}
+		used = fork.MaxBlobGas()
+		parent = fork
+	}
+	if nodeFee != nil && nodeFee.Cmp(out.MaxFeePerBlobGas) > 0 {
+		out.MaxFeePerBlobGas = nodeFee
+	}
+	return out, nil
+}
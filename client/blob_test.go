@@ -0,0 +1,62 @@
+package client
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobBaseFee(t *testing.T) {
+	for excess, fee := range map[uint64]int64{0: 1, 2314057: 1, 2314058: 2, 10 * 1024 * 1024: 23} {
+		require.Equal(t, fee, CalcBlobBaseFee(cancunBlobs, excess).Int64(), excess)
+	}
+
+	// Below target the excess drains to zero; above it grows by the surplus.
+	require.Equal(t, uint64(0), CalcExcessBlobGas(pragueBlobs, pragueBlobs, 0, 5*BlobGasPerBlob, nil))
+	require.Equal(t, uint64(3*BlobGasPerBlob), CalcExcessBlobGas(pragueBlobs, pragueBlobs, 0, 9*BlobGasPerBlob, nil))
+	// EIP-7918: expensive execution gas keeps excess growing at 1/3 of usage.
+	require.Equal(t, uint64(2*BlobGasPerBlob), CalcExcessBlobGas(osakaBlobs, osakaBlobs, 0, 6*BlobGasPerBlob, big.NewInt(1e9)))
+
+	// bpo1 -> bpo2: the parent's blob price (bpo1 fraction) is above the
+	// reserve price, under bpo2's fraction it would be below it.
+	parentExcess, used := uint64(100_000_000), bpo2Blobs.MaxBlobGas()
+	baseFee := big.NewInt(16 * 20_000)
+	require.Greater(t, CalcBlobBaseFee(bpo1Blobs, parentExcess).Int64(), int64(20_000))
+	require.Less(t, CalcBlobBaseFee(bpo2Blobs, parentExcess).Int64(), int64(20_000))
+	require.Equal(t, parentExcess+used-bpo2Blobs.TargetBlobGas(), CalcExcessBlobGas(bpo2Blobs, bpo1Blobs, parentExcess, used, baseFee))
+	require.Equal(t, parentExcess+used*7/21, CalcExcessBlobGas(bpo2Blobs, bpo2Blobs, parentExcess, used, baseFee))
+
+	// Projecting across the boundary uses bpo1 params for the head.
+	head := &BlobGasInfo{Timestamp: 1767747671 - slotSeconds, BaseFee: baseFee, BlobGasUsed: used, ExcessBlobGas: parentExcess}
+	s, err := projectBlobFee(MainnetBlobSchedule, head, 1, nil)
+	require.NoError(t, err)
+	require.Equal(t, "bpo2", s.Fork)
+	require.Equal(t, CalcBlobBaseFee(bpo2Blobs, parentExcess+used-bpo2Blobs.TargetBlobGas()), s.BlobBaseFee)
+
+	fork, ok := MainnetBlobSchedule.At(1746612311)
+	require.True(t, ok)
+	require.Equal(t, "prague", fork.Name)
+	_, ok = MainnetBlobSchedule.At(1710338134)
+	require.False(t, ok)
+}
+
+func TestSuggestBlobFee(t *testing.T) {
+	header := fmt.Sprintf(`{"number":"0x64","timestamp":"0x%x","baseFeePerGas":"0x1","blobGasUsed":"0x%x","excessBlobGas":"0x%x"}`,
+		1746612311+120, 9*BlobGasPerBlob, 40_000_000)
+	srv := newTestServer(t, map[string]string{
+		"eth_chainId":          `"0x1"`,
+		"eth_getBlockByNumber": header,
+		"eth_blobBaseFee":      `{"code":-32601,"message":"method not found"}`,
+	})
+	cli, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	s, err := cli.SuggestBlobFeeContext(t.Context(), 3)
+	require.NoError(t, err)
+	require.Equal(t, "prague", s.Fork)
+	require.Equal(t, CalcBlobBaseFee(pragueBlobs, 40_000_000+3*BlobGasPerBlob), s.BlobBaseFee)
+	require.Equal(t, CalcBlobBaseFee(pragueBlobs, 40_000_000+9*BlobGasPerBlob), s.MaxFeePerBlobGas)
+	require.Equal(t, 1, s.MaxFeePerBlobGas.Cmp(s.BlobBaseFee))
+}
@@ -1,7 +1,13 @@
 package client
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"math/big"
+	"net/http"
+	"time"
 
 	"github.com/gosunuts/ethtxbuilder/utils"
 	"github.com/umbracle/ethgo"
@@ -18,22 +24,36 @@ const (
 )
 
 type Client struct {
+	endpoint     string
 	rpc          *jsonrpc.Client
+	http         *httpTransport // nil for WS/IPC endpoints
+	pool         *pool          // non-nil for multi-endpoint clients
 	ChainId      *big.Int
 	NonceManager *NonceManager
+
+	// Timeout bounds each call whose context has no deadline (0 -> DefaultTimeout).
+	Timeout time.Duration
+	// Retry enables retries of retry-safe failures (nil -> no retries).
+	Retry *RetryPolicy
+	// WSEndpoint is used by the Subscribe methods when the client endpoint
+	// itself is not ws:// or wss://.
+	WSEndpoint string
 }
 
 func NewClient(endpoint string) (*Client, error) {
-	c, err := jsonrpc.NewClient(endpoint)
+	return NewClientContext(context.Background(), endpoint)
+}
+
+// NewClientContext is NewClient with a context for the initial chain id lookup.
+func NewClientContext(ctx context.Context, endpoint string) (*Client, error) {
+	client, err := dial(endpoint)
 	if err != nil {
 		return nil, err
 	}
-	client := &Client{rpc: c}
-	nonceManager := NewNonceManager(client, 0)
-	client.NonceManager = nonceManager
 
-	chainID, err := c.Eth().ChainID()
+	chainID, err := client.ChainIDContext(ctx)
 	if err != nil {
+		client.Close()
 		return nil, err
 	}
 	client.ChainId = chainID
@@ -41,59 +61,169 @@ func NewClient(endpoint string) (*Client, error) {
 	return client, nil
 }
 
+// dial connects to a single endpoint without any RPC round-trip.
+func dial(endpoint string) (*Client, error) {
+	c, err := jsonrpc.NewClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	client := &Client{endpoint: endpoint, rpc: c}
+	if isHTTPEndpoint(endpoint) {
+		client.http = &httpTransport{url: endpoint, client: &http.Client{}}
+	}
+	client.NonceManager = NewNonceManager(client, 0)
+	return client, nil
+}
+
 func (c *Client) Close() error {
+	if c.pool != nil {
+		return c.pool.close()
+	}
 	return c.rpc.Close()
 }
 
 // ChainID returns the current chain id.
 func (c *Client) ChainID() (*big.Int, error) {
-	return c.rpc.Eth().ChainID()
+	return c.ChainIDContext(context.Background())
+}
+
+// ChainIDContext is ChainID with a context.
+func (c *Client) ChainIDContext(ctx context.Context) (*big.Int, error) {
+	var out string
+	if err := c.RawCallContext(ctx, "eth_chainId", &out); err != nil {
+		return nil, err
+	}
+	return utils.StrToBig(out)
 }
 
 // BlockNumber returns the latest block number.
 func (c *Client) BlockNumber() (uint64, error) {
-	return c.rpc.Eth().BlockNumber()
+	return c.BlockNumberContext(context.Background())
+}
+
+// BlockNumberContext is BlockNumber with a context.
+func (c *Client) BlockNumberContext(ctx context.Context) (uint64, error) {
+	var out string
+	if err := c.RawCallContext(ctx, "eth_blockNumber", &out); err != nil {
+		return 0, err
+	}
+	return utils.StrToU64(out)
 }
 
 // BlockByNumber fetches a full block by number (nil -> latest).
 func (c *Client) BlockByNumber(n ethgo.BlockNumber, full bool) (*ethgo.Block, error) {
-	return c.rpc.Eth().GetBlockByNumber(n, full)
+	return c.BlockByNumberContext(context.Background(), n, full)
+}
+
+// BlockByNumberContext is BlockByNumber with a context.
+func (c *Client) BlockByNumberContext(ctx context.Context, n ethgo.BlockNumber, full bool) (*ethgo.Block, error) {
+	var b *ethgo.Block
+	if err := c.RawCallContext(ctx, "eth_getBlockByNumber", &b, n.String(), full); err != nil {
+		return nil, err
+	}
+	return b, nil
 }
 
 // BalanceAt reads an account balance at a block (nil -> latest).
 func (c *Client) BalanceAt(addr string, block ethgo.BlockNumberOrHash) (*big.Int, error) {
-	return c.rpc.Eth().GetBalance(ethgo.HexToAddress(addr), block)
+	return c.BalanceAtContext(context.Background(), addr, block)
+}
+
+// BalanceAtContext is BalanceAt with a context.
+func (c *Client) BalanceAtContext(ctx context.Context, addr string, block ethgo.BlockNumberOrHash) (*big.Int, error) {
+	var out string
+	if err := c.criticalRead(ctx, "eth_getBalance", &out, ethgo.HexToAddress(addr), location(block)); err != nil {
+		return nil, err
+	}
+	return utils.StrToBig(out)
 }
 
 // NonceAt returns the account nonce at a given block (nil -> latest).
 func (c *Client) NonceAt(addr string, block ethgo.BlockNumberOrHash) (uint64, error) {
-	return c.rpc.Eth().GetNonce(ethgo.HexToAddress(addr), block)
+	return c.NonceAtContext(context.Background(), addr, block)
+}
+
+// NonceAtContext is NonceAt with a context.
+func (c *Client) NonceAtContext(ctx context.Context, addr string, block ethgo.BlockNumberOrHash) (uint64, error) {
+	var out string
+	if err := c.criticalRead(ctx, "eth_getTransactionCount", &out, ethgo.HexToAddress(addr), location(block)); err != nil {
+		return 0, err
+	}
+	return utils.StrToU64(out)
+}
+
+// CodeAt returns the contract code at a given block (empty for EOAs).
+func (c *Client) CodeAt(addr string, block ethgo.BlockNumberOrHash) ([]byte, error) {
+	return c.CodeAtContext(context.Background(), addr, block)
+}
+
+// CodeAtContext is CodeAt with a context.
+func (c *Client) CodeAtContext(ctx context.Context, addr string, block ethgo.BlockNumberOrHash) ([]byte, error) {
+	var code string
+	if err := c.RawCallContext(ctx, "eth_getCode", &code, ethgo.HexToAddress(addr), location(block)); err != nil {
+		return nil, err
+	}
+	return utils.FromHex(code)
+}
+
+// location renders a block selector for JSON-RPC (nil -> latest).
+func location(block ethgo.BlockNumberOrHash) string {
+	if block == nil {
+		return ethgo.Latest.Location()
+	}
+	return block.Location()
 }
 
 /* ---------- Gas/fees ---------- */
 
 // SuggestGasPrice returns the legacy gas price (pre-1559 fallback).
 func (c *Client) SuggestGasPrice() (*big.Int, error) {
-	maxGasPrice, err := c.rpc.Eth().GasPrice()
-	if err != nil {
+	return c.SuggestGasPriceContext(context.Background())
+}
+
+// SuggestGasPriceContext is SuggestGasPrice with a context.
+func (c *Client) SuggestGasPriceContext(ctx context.Context) (*big.Int, error) {
+	var out string
+	if err := c.RawCallContext(ctx, "eth_gasPrice", &out); err != nil {
 		return nil, err
 	}
-	return utils.U64ToBig(maxGasPrice), nil
+	return utils.StrToBig(out)
 }
 
 // SuggestGasTipCap returns the EIP-1559 priority fee per gas.
 func (c *Client) SuggestGasTipCap() (*big.Int, error) {
+	return c.SuggestGasTipCapContext(context.Background())
+}
+
+// SuggestGasTipCapContext is SuggestGasTipCap with a context.
+// Nodes without eth_maxPriorityFeePerGas get the FeeOracle standard tip.
+func (c *Client) SuggestGasTipCapContext(ctx context.Context) (*big.Int, error) {
 	var out string
-	// Not all nodes support eth_maxPriorityFeePerGas, so handle fallback outside.
-	if err := c.rpc.Call("eth_maxPriorityFeePerGas", &out); err != nil {
-		return nil, err
+	if err := c.RawCallContext(ctx, "eth_maxPriorityFeePerGas", &out); err != nil {
+		if !errors.Is(err, ErrMethodNotSupported) {
+			return nil, err
+		}
+		fee, ferr := NewFeeOracle(c).Suggest(ctx, FeeStandard)
+		if ferr != nil {
+			return nil, fmt.Errorf("%w; fee oracle: %w", err, ferr)
+		}
+		return fee.TipCap, nil
 	}
 	return utils.StrToBig(out)
 }
 
 // FeeHistory returns EIP-1559 fee history.
 func (c *Client) FeeHistory(from, to ethgo.BlockNumber) (*jsonrpc.FeeHistory, error) {
-	return c.rpc.Eth().FeeHistory(from, to)
+	return c.FeeHistoryContext(context.Background(), from, to)
+}
+
+// FeeHistoryContext is FeeHistory with a context.
+func (c *Client) FeeHistoryContext(ctx context.Context, from, to ethgo.BlockNumber) (*jsonrpc.FeeHistory, error) {
+	var out *jsonrpc.FeeHistory
+	if err := c.RawCallContext(ctx, "eth_feeHistory", &out, from.String(), to.String(), nil); err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
 /* ---------- Call / Estimate ---------- */
@@ -103,34 +233,97 @@ type CallMsg = ethgo.CallMsg
 
 // EstimateGas simulates a tx and returns the needed gas.
 func (c *Client) EstimateGas(msg *CallMsg) (uint64, error) {
-	return c.rpc.Eth().EstimateGas(msg)
+	return c.EstimateGasContext(context.Background(), msg)
+}
+
+// EstimateGasContext is EstimateGas with a context.
+func (c *Client) EstimateGasContext(ctx context.Context, msg *CallMsg) (uint64, error) {
+	var out string
+	if err := c.RawCallContext(ctx, "eth_estimateGas", &out, msg); err != nil {
+		return 0, err
+	}
+	return utils.StrToU64(out)
 }
 
 // Call executes a read-only call (eth_call).
 func (c *Client) Call(msg *CallMsg, block ethgo.BlockNumber) (string, error) {
-	return c.rpc.Eth().Call(msg, block)
+	return c.CallContext(context.Background(), msg, block)
+}
+
+// CallContext is Call with a context.
+func (c *Client) CallContext(ctx context.Context, msg *CallMsg, block ethgo.BlockNumber) (string, error) {
+	var out string
+	if err := c.RawCallContext(ctx, "eth_call", &out, msg, block.String()); err != nil {
+		return "", err
+	}
+	return out, nil
 }
 
 /* ---------- Tx send / receipt ---------- */
 
 // SendRawTransaction broadcasts a signed raw tx (RLP) and returns its hash.
 func (c *Client) SendRawTransaction(rawTx []byte) (ethgo.Hash, error) {
-	return c.rpc.Eth().SendRawTransaction(rawTx)
+	return c.SendRawTransactionContext(context.Background(), rawTx)
+}
+
+// SendRawTransactionContext is SendRawTransaction with a context.
+// Multi-endpoint clients send to every endpoint at once.
+//
+// A cancelled send may still have reached the node; callers should treat the
+// outcome as unknown rather than failed.
+func (c *Client) SendRawTransactionContext(ctx context.Context, rawTx []byte) (ethgo.Hash, error) {
+	var h ethgo.Hash
+	raw := "0x" + hex.EncodeToString(rawTx)
+	if c.pool != nil {
+		ctx, cancel := c.withTimeout(ctx)
+		defer cancel()
+		if err := c.pool.broadcast(ctx, "eth_sendRawTransaction", &h, raw); err != nil {
+			return ethgo.Hash{}, err
+		}
+		return h, nil
+	}
+	if err := c.RawCallContext(ctx, "eth_sendRawTransaction", &h, raw); err != nil {
+		return ethgo.Hash{}, err
+	}
+	return h, nil
 }
 
 // TransactionReceipt fetches the receipt for a mined tx (may be nil before mined).
 func (c *Client) TransactionReceipt(h ethgo.Hash) (*ethgo.Receipt, error) {
-	return c.rpc.Eth().GetTransactionReceipt(h)
+	return c.TransactionReceiptContext(context.Background(), h)
 }
 
-/* ---------- Logs / Subscribe (WS endpoint required) ---------- */
+// TransactionReceiptContext is TransactionReceipt with a context.
+func (c *Client) TransactionReceiptContext(ctx context.Context, h ethgo.Hash) (*ethgo.Receipt, error) {
+	var r *ethgo.Receipt
+	if err := c.RawCallContext(ctx, "eth_getTransactionReceipt", &r, h); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+/* ---------- Logs (subscriptions: see subscribe.go) ---------- */
 
 // FilterQuery is re-exported for convenience.
 type FilterQuery = ethgo.LogFilter
 
-// FilterLogs executes a one-off logs query (eth_getLogs).
+// FilterLogs executes a one-off logs query (eth_getLogs). Use FetchLogs for
+// ranges wider than the provider accepts in one request.
 func (c *Client) FilterLogs(q *FilterQuery) ([]*ethgo.Log, error) {
-	return c.rpc.Eth().GetLogs(q)
+	return c.FilterLogsContext(context.Background(), q)
+}
+
+// FilterLogsContext is FilterLogs with a context.
+func (c *Client) FilterLogsContext(ctx context.Context, q *FilterQuery) ([]*ethgo.Log, error) {
+	params := filterParams(q, q.From, q.To)
+	if q.BlockHash != nil {
+		params["blockHash"] = q.BlockHash
+	}
+	var out []*ethgo.Log
+	if err := c.RawCallContext(ctx, "eth_getLogs", &out, params); err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
 /* ---------- Helpers ---------- */
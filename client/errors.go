@@ -0,0 +1,206 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+// Node errors, normalised across geth, Nethermind, Erigon, Besu, Reth and
+// L2 nodes. Match them with errors.Is; the original *codec.ErrorObject or
+// *HTTPError stays reachable through errors.As.
+var (
+	ErrNonceTooLow            = errors.New("nonce too low")
+	ErrNonceTooHigh           = errors.New("nonce too high")
+	ErrUnderpriced            = errors.New("transaction underpriced")
+	ErrReplacementUnderpriced = errors.New("replacement transaction underpriced")
+	ErrInsufficientFunds      = errors.New("insufficient funds")
+	ErrAlreadyKnown           = errors.New("already known")
+	ErrIntrinsicGasTooLow     = errors.New("intrinsic gas too low")
+	ErrExecutionReverted      = errors.New("execution reverted")
+	ErrRateLimited            = errors.New("rate limited")
+	ErrMethodNotSupported     = errors.New("method not supported")
+)
+
+// RPCError is a node error classified as one of the ErrXxx values.
+type RPCError struct {
+	Kind error // ErrNonceTooLow, ErrRateLimited, ...
+	Err  error // *codec.ErrorObject, *HTTPError or a transport error
+}
+
+// Error returns the node's original message.
+func (e *RPCError) Error() string { return e.Err.Error() }
+
+func (e *RPCError) Unwrap() []error { return []error{e.Kind, e.Err} }
+
+// JSON-RPC error codes with a fixed meaning.
+const (
+	codeExecutionReverted = 3      // geth, Erigon, Reth, Besu eth_call/eth_estimateGas
+	codeLimitExceeded     = -32005 // EIP-1474; Infura and others for rate limits
+	codeRateLimited       = 429    // some providers echo the HTTP status
+	codeMethodNotFound    = -32601 // JSON-RPC 2.0
+)
+
+// Message fragments per error, lower-cased. Order matters: reverts are
+// checked first (revert reasons are free text) and replacement before plain
+// underpriced.
+var errorPatterns = []struct {
+	kind    error
+	needles []string
+}{
+	{ErrExecutionReverted, []string{
+		"execution reverted", // geth, Erigon, Reth, Besu, OP, Arbitrum
+		"reverted",           // Nethermind "Reverted 0x..."
+		"vm execution error", // Nethermind, Parity
+		"vm exception while processing transaction: revert", // Ganache
+	}},
+	{ErrRateLimited, []string{
+		"rate limit",
+		"too many requests",
+		"request rate exceeded",
+		"daily request count exceeded",
+		"exceeded its compute units",
+		"capacity exceeded",
+	}},
+	{ErrMethodNotSupported, []string{
+		"method not found",                  // geth, Erigon, Reth
+		"does not exist/is not available",   // Infura, Nethermind
+		"unsupported method",                // Alchemy, QuickNode
+		"method not supported",              // various providers
+		"method not available",              // Besu
+		"is not available on the free tier", // paid-plan-only methods
+	}},
+	{ErrReplacementUnderpriced, []string{
+		"replacement transaction underpriced", // geth, Erigon, Reth, OP
+		"replacement_underpriced",             // Besu REPLACEMENT_UNDERPRICED
+		"replacementnotallowed",               // Nethermind
+		"could not replace existing tx",       // Arbitrum sequencer
+	}},
+	{ErrNonceTooLow, []string{
+		"nonce too low",    // geth, Erigon, Reth, Arbitrum
+		"nonce_too_low",    // Besu NONCE_TOO_LOW
+		"oldnonce",         // Nethermind OldNonce
+		"nonce is too low", // Reth
+		"transaction nonce is too low",
+		"nonce has already been used", // various wallets / L2s
+	}},
+	{ErrNonceTooHigh, []string{
+		"nonce too high", // geth, Erigon, Arbitrum
+		"transaction nonce is too high",
+		"nonce_too_far_in_future", // Besu
+		"noncegap",                // Nethermind NonceGap
+		"nonce gap",
+	}},
+	{ErrAlreadyKnown, []string{
+		"already known",                  // geth, Erigon, Reth, OP
+		"known transaction",              // older geth, Parity
+		"alreadyknown",                   // Nethermind AlreadyKnown
+		"transaction_already_known",      // Besu
+		"already imported",               // Parity/OpenEthereum
+		"transaction already in mempool", // Erigon txpool
+	}},
+	{ErrInsufficientFunds, []string{
+		"insufficient funds",           // geth, Erigon, Reth, OP, Arbitrum
+		"insufficientfunds",            // Nethermind
+		"upfront_cost_exceeds_balance", // Besu
+		"upfront cost exceeds account balance",
+		"insufficient balance",
+	}},
+	{ErrIntrinsicGasTooLow, []string{
+		"intrinsic gas too low",           // geth, Erigon, Reth, Arbitrum
+		"intrinsic_gas_exceeds_gas_limit", // Besu
+		"intrinsicgastoolow",              // Nethermind
+		"gaslimitbelowintrinsic",
+		"gas limit is too low",
+	}},
+	{ErrUnderpriced, []string{
+		"transaction underpriced",                  // geth, Erigon, Reth, OP
+		"max fee per gas less than block base fee", // geth, Arbitrum
+		"fee cap less than block base fee",
+		"feetoolow", // Nethermind FeeTooLow
+		"fee too low",
+		"gas_price_too_low", // Besu
+		"gas price below configured minimum",
+		"gas price too low",
+		"underpriced",
+	}},
+}
+
+// Classify returns the ErrXxx value matching err, or nil. It accepts errors
+// from this package as well as plain errors carrying a node message.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Kind
+	}
+	var revErr *RevertError
+	if errors.As(err, &revErr) {
+		return ErrExecutionReverted
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+
+	msg := err.Error()
+	var obj *codec.ErrorObject
+	if errors.As(err, &obj) {
+		switch obj.Code {
+		case codeExecutionReverted:
+			return ErrExecutionReverted
+		case codeRateLimited:
+			return ErrRateLimited
+		case codeMethodNotFound:
+			return ErrMethodNotSupported
+		}
+		msg = obj.Message
+		if s, ok := obj.Data.(string); ok {
+			msg += " " + s
+		}
+		if obj.Code == codeLimitExceeded && !isRangeLimit(msg) {
+			return ErrRateLimited
+		}
+	}
+
+	msg = strings.ToLower(msg)
+	for _, p := range errorPatterns {
+		for _, n := range p.needles {
+			if strings.Contains(msg, n) {
+				return p.kind
+			}
+		}
+	}
+	return nil
+}
+
+// isRangeLimit reports whether a -32005 message is about eth_getLogs result
+// size rather than request rate.
+func isRangeLimit(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "results") || strings.Contains(msg, "block range") || strings.Contains(msg, "response size")
+}
+
+// wrapError attaches a classification to err when one applies; reverts carry
+// their decoded *RevertError.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+	kind := Classify(err)
+	if kind == ErrExecutionReverted {
+		return &RPCError{Kind: kind, Err: RevertFromError(err)}
+	}
+	if kind != nil {
+		return &RPCError{Kind: kind, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+func TestClassify(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want error
+	}{
+		{&codec.ErrorObject{Code: -32000, Message: "nonce too low: next nonce 5, tx nonce 4"}, ErrNonceTooLow},
+		{&codec.ErrorObject{Code: -32010, Message: "OldNonce, Current nonce: 5"}, ErrNonceTooLow},
+		{&codec.ErrorObject{Code: -32000, Message: "Transaction nonce is too high"}, ErrNonceTooHigh},
+		{&codec.ErrorObject{Code: -32000, Message: "replacement transaction underpriced"}, ErrReplacementUnderpriced},
+		{&codec.ErrorObject{Code: -32000, Message: "REPLACEMENT_UNDERPRICED"}, ErrReplacementUnderpriced},
+		{&codec.ErrorObject{Code: -32000, Message: "max fee per gas less than block base fee"}, ErrUnderpriced},
+		{&codec.ErrorObject{Code: -32000, Message: "FeeTooLow"}, ErrUnderpriced},
+		{&codec.ErrorObject{Code: -32000, Message: "insufficient funds for gas * price + value"}, ErrInsufficientFunds},
+		{&codec.ErrorObject{Code: -32004, Message: "UPFRONT_COST_EXCEEDS_BALANCE"}, ErrInsufficientFunds},
+		{&codec.ErrorObject{Code: -32000, Message: "already known"}, ErrAlreadyKnown},
+		{&codec.ErrorObject{Code: -32000, Message: "AlreadyKnown"}, ErrAlreadyKnown},
+		{&codec.ErrorObject{Code: -32000, Message: "intrinsic gas too low"}, ErrIntrinsicGasTooLow},
+		{&codec.ErrorObject{Code: 3, Message: "execution reverted: insufficient funds"}, ErrExecutionReverted},
+		{&codec.ErrorObject{Code: -32005, Message: "daily request count exceeded"}, ErrRateLimited},
+		{&codec.ErrorObject{Code: -32005, Message: "query returned more than 10000 results"}, nil},
+		{&HTTPError{StatusCode: http.StatusTooManyRequests}, ErrRateLimited},
+		{&codec.ErrorObject{Code: -32601, Message: "the method eth_createAccessList does not exist/is not available"}, ErrMethodNotSupported},
+		{&codec.ErrorObject{Code: -32600, Message: "Unsupported method: eth_simulateV1"}, ErrMethodNotSupported},
+		{errors.New("nonce too low"), ErrNonceTooLow},
+		{errors.New("connection refused"), nil},
+	} {
+		require.Equal(t, test.want, Classify(test.err), test.err.Error())
+	}
+
+	err := wrapError(&codec.ErrorObject{Code: -32000, Message: "nonce too low"})
+	require.ErrorIs(t, err, ErrNonceTooLow)
+	var obj *codec.ErrorObject
+	require.ErrorAs(t, err, &obj)
+}
+
+func TestRetry(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`)
+	}))
+	defer srv.Close()
+
+	cli, err := dial(srv.URL)
+	require.NoError(t, err)
+
+	_, err = cli.BlockNumber()
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	cli.Retry = &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	n, err := cli.BlockNumber()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), n)
+	require.Equal(t, int32(3), calls.Load())
+
+	reset := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	require.False(t, Retryable("eth_sendRawTransaction", reset))
+	require.True(t, Retryable("eth_getBalance", reset))
+	require.True(t, Retryable("eth_getBalance", io.ErrUnexpectedEOF))
+	require.False(t, Retryable("eth_getBalance", &HTTPError{StatusCode: http.StatusBadRequest}))
+	require.True(t, Retryable("eth_getBalance", &HTTPError{StatusCode: http.StatusBadGateway}))
+}
+
+func TestRetryDecodeError(t *testing.T) {
+	// A well-formed response whose result does not decode is an answer.
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{"number":"0x1"}}`)
+	}))
+	defer srv.Close()
+
+	cli, err := dial(srv.URL)
+	require.NoError(t, err)
+	cli.Retry = &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	var out string
+	err = cli.RawCall("eth_blockNumber", &out)
+	var typeErr *json.UnmarshalTypeError
+	require.ErrorAs(t, err, &typeErr)
+	require.False(t, Retryable("eth_blockNumber", err))
+	require.Equal(t, int32(1), calls.Load())
+}
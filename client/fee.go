@@ -0,0 +1,215 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc"
+)
+
+// FeeProfile trades inclusion speed for price.
+type FeeProfile int
+
+const (
+	FeeSlow FeeProfile = iota
+	FeeStandard
+	FeeFast
+)
+
+func (p FeeProfile) String() string {
+	switch p {
+	case FeeSlow:
+		return "slow"
+	case FeeStandard:
+		return "standard"
+	case FeeFast:
+		return "fast"
+	}
+	return "unknown"
+}
+
+// FeeSuggestion is a fee quote for one profile.
+//
+// On EIP-1559 chains TipCap/FeeCap are set and GasPrice is nil. On chains
+// without EIP-1559 Legacy is true, GasPrice is set and TipCap/FeeCap both
+// equal GasPrice (which a 1559 tx would pay in full).
+type FeeSuggestion struct {
+	Profile  FeeProfile
+	BaseFee  *big.Int // next block's base fee; nil on legacy chains
+	TipCap   *big.Int // maxPriorityFeePerGas
+	FeeCap   *big.Int // maxFeePerGas
+	GasPrice *big.Int
+	Legacy   bool
+}
+
+// FeeOracle suggests fees from eth_feeHistory reward percentiles and the
+// pending base fee. Fields may be changed after NewFeeOracle.
+type FeeOracle struct {
+	c *Client
+
+	// Blocks of history to sample (default 20).
+	Blocks uint64
+	// Reward percentiles for slow, standard and fast (default 10, 50, 90).
+	Percentiles [3]float64
+	// BaseFeeMultiplier scales the base fee in FeeCap to absorb base-fee
+	// growth before inclusion (default 2; each full block adds 12.5%).
+	BaseFeeMultiplier float64
+	// LegacyMultipliers scale eth_gasPrice on legacy chains (default 1, 1.1, 1.25).
+	LegacyMultipliers [3]float64
+
+	MinTip    *big.Int // floor for TipCap (nil -> none)
+	MaxTip    *big.Int // cap for TipCap (nil -> none)
+	MaxFeeCap *big.Int // cap for FeeCap and GasPrice (nil -> none)
+}
+
+// NewFeeOracle returns an oracle with default settings.
+func NewFeeOracle(c *Client) *FeeOracle {
+	return &FeeOracle{
+		c:                 c,
+		Blocks:            20,
+		Percentiles:       [3]float64{10, 50, 90},
+		BaseFeeMultiplier: 2,
+		LegacyMultipliers: [3]float64{1, 1.1, 1.25},
+	}
+}
+
+// Suggest returns the fees for one profile.
+func (o *FeeOracle) Suggest(ctx context.Context, p FeeProfile) (*FeeSuggestion, error) {
+	if p < FeeSlow || p > FeeFast {
+		return nil, fmt.Errorf("unknown fee profile %d", p)
+	}
+	all, err := o.SuggestAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return all[p], nil
+}
+
+// SuggestAll returns slow, standard and fast fees from one fee-history read.
+func (o *FeeOracle) SuggestAll(ctx context.Context) ([3]*FeeSuggestion, error) {
+	var out [3]*FeeSuggestion
+	hist, err := o.c.FeeHistoryPercentilesContext(ctx, o.Blocks, ethgo.Latest, o.Percentiles[:])
+	if err != nil {
+		if errors.Is(err, ErrMethodNotSupported) {
+			return o.legacy(ctx) // eth_feeHistory unsupported
+		}
+		return out, err
+	}
+	if len(hist.BaseFee) == 0 || hist.BaseFee[len(hist.BaseFee)-1].Sign() == 0 {
+		return o.legacy(ctx) // pre-London or no base fee
+	}
+	baseFee := hist.BaseFee[len(hist.BaseFee)-1]
+
+	var fallbackTip *big.Int
+	for i := range out {
+		tip := medianReward(hist, i)
+		if tip == nil {
+			if fallbackTip == nil {
+				fallbackTip = o.nodeTip(ctx)
+			}
+			tip = new(big.Int).Set(fallbackTip)
+		}
+		if o.MinTip != nil && tip.Cmp(o.MinTip) < 0 {
+			tip.Set(o.MinTip)
+		}
+		if o.MaxTip != nil && tip.Cmp(o.MaxTip) > 0 {
+			tip.Set(o.MaxTip)
+		}
+
+		feeCap := mulFloat(baseFee, o.BaseFeeMultiplier)
+		feeCap.Add(feeCap, tip)
+		if o.MaxFeeCap != nil && feeCap.Cmp(o.MaxFeeCap) > 0 {
+			feeCap.Set(o.MaxFeeCap)
+		}
+		if tip.Cmp(feeCap) > 0 {
+			tip.Set(feeCap)
+		}
+		out[i] = &FeeSuggestion{Profile: FeeProfile(i), BaseFee: baseFee, TipCap: tip, FeeCap: feeCap}
+	}
+	return out, nil
+}
+
+func (o *FeeOracle) legacy(ctx context.Context) ([3]*FeeSuggestion, error) {
+	var out [3]*FeeSuggestion
+	gp, err := o.c.SuggestGasPriceContext(ctx)
+	if err != nil {
+		return out, err
+	}
+	for i := range out {
+		price := mulFloat(gp, o.LegacyMultipliers[i])
+		if o.MaxFeeCap != nil && price.Cmp(o.MaxFeeCap) > 0 {
+			price.Set(o.MaxFeeCap)
+		}
+		out[i] = &FeeSuggestion{
+			Profile:  FeeProfile(i),
+			TipCap:   price,
+			FeeCap:   price,
+			GasPrice: price,
+			Legacy:   true,
+		}
+	}
+	return out, nil
+}
+
+// nodeTip asks the node for a tip when recent blocks carry no rewards.
+func (o *FeeOracle) nodeTip(ctx context.Context) *big.Int {
+	var s string
+	if err := o.c.RawCallContext(ctx, "eth_maxPriorityFeePerGas", &s); err == nil {
+		if v, ok := new(big.Int).SetString(s, 0); ok {
+			return v
+		}
+	}
+	return new(big.Int)
+}
+
+// medianReward returns the median of percentile column i over non-empty
+// blocks, or nil when there is none.
+func medianReward(h *jsonrpc.FeeHistory, i int) *big.Int {
+	var vals []*big.Int
+	for b, r := range h.Reward {
+		if b < len(h.GasUsedRatio) && h.GasUsedRatio[b] == 0 {
+			continue
+		}
+		if i < len(r) && r[i] != nil {
+			vals = append(vals, r[i])
+		}
+	}
+	if len(vals) == 0 {
+		return nil
+	}
+	sort.Slice(vals, func(a, b int) bool { return vals[a].Cmp(vals[b]) < 0 })
+	return new(big.Int).Set(vals[len(vals)/2])
+}
+
+func mulFloat(x *big.Int, f float64) *big.Int {
+	if f <= 0 {
+		f = 1
+	}
+	v, _ := new(big.Float).Mul(new(big.Float).SetInt(x), big.NewFloat(f)).Int(nil)
+	return v
+}
+
+/* ---------- Fee history with rewards ---------- */
+
+// FeeHistoryPercentiles returns blocks of fee history ending at newest with
+// the given reward percentiles. BaseFee has one extra entry: the base fee of
+// the block after newest.
+func (c *Client) FeeHistoryPercentiles(blocks uint64, newest ethgo.BlockNumber, percentiles []float64) (*jsonrpc.FeeHistory, error) {
+	return c.FeeHistoryPercentilesContext(context.Background(), blocks, newest, percentiles)
+}
+
+// FeeHistoryPercentilesContext is FeeHistoryPercentiles with a context.
+func (c *Client) FeeHistoryPercentilesContext(ctx context.Context, blocks uint64, newest ethgo.BlockNumber, percentiles []float64) (*jsonrpc.FeeHistory, error) {
+	var out *jsonrpc.FeeHistory
+	if err := c.RawCallContext(ctx, "eth_feeHistory", &out, fmt.Sprintf("0x%x", blocks), newest.String(), percentiles); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return nil, errors.New("empty fee history")
+	}
+	return out, nil
+}
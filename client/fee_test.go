@@ -0,0 +1,80 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeOracle(t *testing.T) {
+	srv := newTestServer(t, map[string]string{
+		"eth_chainId": `"0x1"`,
+		"eth_feeHistory": `{"oldestBlock":"0x10","baseFeePerGas":["0x64","0x64","0x64","0xc8"],"gasUsedRatio":[0.5,0,0.5],` +
+			`"reward":[["0x1","0x5","0xa"],["0x0","0x0","0x0"],["0x3","0x7","0x14"]]}`,
+	})
+	cli, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	all, err := NewFeeOracle(cli).SuggestAll(t.Context())
+	require.NoError(t, err)
+	// Empty block 0x11 is skipped; the median of two values is the upper one.
+	for i, tip := range []int64{3, 7, 20} {
+		s := all[i]
+		require.False(t, s.Legacy)
+		require.Equal(t, int64(200), s.BaseFee.Int64())
+		require.Equal(t, tip, s.TipCap.Int64())
+		require.Equal(t, 400+tip, s.FeeCap.Int64())
+	}
+
+	legacy := newTestServer(t, map[string]string{
+		"eth_chainId":    `"0x1"`,
+		"eth_feeHistory": `{"code":-32601,"message":"method not found"}`,
+		"eth_gasPrice":   `"0x64"`,
+	})
+	cli, err = NewClient(legacy.URL)
+	require.NoError(t, err)
+	fast, err := NewFeeOracle(cli).Suggest(t.Context(), FeeFast)
+	require.NoError(t, err)
+	require.True(t, fast.Legacy)
+	require.Equal(t, int64(125), fast.GasPrice.Int64())
+}
+
+func TestFeeOracleErrors(t *testing.T) {
+	// A throttled eth_feeHistory is returned, not downgraded to a legacy fee.
+	srv := newTestServer(t, map[string]string{
+		"eth_chainId":              `"0x1"`,
+		"eth_feeHistory":           `{"code":-32005,"message":"daily request count exceeded, request rate limited"}`,
+		"eth_gasPrice":             `"0x64"`,
+		"eth_maxPriorityFeePerGas": `{"code":-32005,"message":"daily request count exceeded, request rate limited"}`,
+	})
+	cli, err := NewClient(srv.URL)
+	require.NoError(t, err)
+	_, err = NewFeeOracle(cli).SuggestAll(t.Context())
+	require.ErrorIs(t, err, ErrRateLimited)
+	_, err = cli.SuggestGasTipCapContext(t.Context())
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	// Without eth_maxPriorityFeePerGas the oracle's standard tip is used.
+	srv = newTestServer(t, map[string]string{
+		"eth_chainId":              `"0x1"`,
+		"eth_feeHistory":           `{"oldestBlock":"0x10","baseFeePerGas":["0x64","0x64"],"gasUsedRatio":[0.5],"reward":[["0x1","0x5","0xa"]]}`,
+		"eth_maxPriorityFeePerGas": `{"code":-32601,"message":"the method eth_maxPriorityFeePerGas does not exist/is not available"}`,
+	})
+	cli, err = NewClient(srv.URL)
+	require.NoError(t, err)
+	tip, err := cli.SuggestGasTipCapContext(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, int64(5), tip.Int64())
+
+	// When the oracle fails too, both errors are reported.
+	srv = newTestServer(t, map[string]string{
+		"eth_chainId":              `"0x1"`,
+		"eth_feeHistory":           `{"code":-32000,"message":"header not found"}`,
+		"eth_maxPriorityFeePerGas": `{"code":-32601,"message":"method not found"}`,
+	})
+	cli, err = NewClient(srv.URL)
+	require.NoError(t, err)
+	_, err = cli.SuggestGasTipCapContext(t.Context())
+	require.ErrorIs(t, err, ErrMethodNotSupported)
+	require.ErrorContains(t, err, "header not found")
+}
@@ -0,0 +1,262 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+// Defaults for LogOptions.
+const (
+	DefaultLogChunkSize   = 2000
+	DefaultLogConcurrency = 4
+)
+
+// LogOptions configures FetchLogs and StreamLogs.
+type LogOptions struct {
+	// ChunkSize is the number of blocks per eth_getLogs request
+	// (0 -> DefaultLogChunkSize). Ranges the provider rejects as too large
+	// are halved, and later chunks start at the reduced size.
+	ChunkSize uint64
+	// Concurrency is how many chunks are fetched at once
+	// (0 -> DefaultLogConcurrency).
+	Concurrency int
+}
+
+// LogChunk is the logs of one block range, From and To inclusive.
+type LogChunk struct {
+	From uint64
+	To   uint64
+	Logs []*Log
+}
+
+// FetchLogs is FilterLogs over a block range of any size, split into chunks
+// the provider accepts.
+func (c *Client) FetchLogs(q *FilterQuery, opts *LogOptions) ([]*Log, error) {
+	return c.FetchLogsContext(context.Background(), q, opts)
+}
+
+// FetchLogsContext is FetchLogs with a context.
+func (c *Client) FetchLogsContext(ctx context.Context, q *FilterQuery, opts *LogOptions) ([]*Log, error) {
+	if q.BlockHash != nil {
+		return c.FilterLogsContext(ctx, q)
+	}
+	var out []*Log
+	err := c.StreamLogs(ctx, q, opts, func(ch LogChunk) error {
+		out = append(out, ch.Logs...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StreamLogs fetches the logs matching q chunk by chunk and calls fn for
+// each chunk in block order, so a caller can checkpoint after every call.
+// Up to opts.Concurrency chunks are requested ahead of the one being
+// delivered. A chunk rejected for its range or result size ("query
+// returned more than 10000 results", "block range too large", ...) is
+// split in half until it fits or spans a single block.
+//
+// q.From and q.To default to latest, as in eth_getLogs; tags are resolved
+// to numbers once at the start. The first error from a request or from fn
+// stops the stream and is returned.
+func (c *Client) StreamLogs(ctx context.Context, q *FilterQuery, opts *LogOptions, fn func(LogChunk) error) error {
+	if q.BlockHash != nil {
+		return errors.New("StreamLogs: block hash queries have no range; use FilterLogs")
+	}
+	f := &logFetcher{c: c, q: q}
+	if opts != nil {
+		f.opts = *opts
+	}
+	if f.opts.ChunkSize == 0 {
+		f.opts.ChunkSize = DefaultLogChunkSize
+	}
+	if f.opts.Concurrency <= 0 {
+		f.opts.Concurrency = DefaultLogConcurrency
+	}
+	f.size.Store(f.opts.ChunkSize)
+
+	from, err := f.resolve(ctx, q.From)
+	if err != nil {
+		return err
+	}
+	to, err := f.resolve(ctx, q.To)
+	if err != nil {
+		return err
+	}
+	if from > to {
+		return nil
+	}
+	return f.run(ctx, from, to, fn)
+}
+
+type logFetcher struct {
+	c    *Client
+	q    *FilterQuery
+	opts LogOptions
+
+	size atomic.Uint64 // blocks per new chunk; only shrinks
+	head uint64        // resolved latest block, 0 until needed
+}
+
+type logTask struct {
+	chunk LogChunk
+	err   error
+	done  chan struct{}
+}
+
+// resolve turns a block number or tag into a height.
+func (f *logFetcher) resolve(ctx context.Context, n *ethgo.BlockNumber) (uint64, error) {
+	if n != nil && *n >= 0 {
+		return uint64(*n), nil
+	}
+	if n != nil && *n == ethgo.Earliest {
+		return 0, nil
+	}
+	if f.head == 0 {
+		head, err := f.c.BlockNumberContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+		f.head = head
+	}
+	return f.head, nil
+}
+
+func (f *logFetcher) run(ctx context.Context, from, to uint64, fn func(LogChunk) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	// sem bounds the chunks in flight or waiting for delivery; a slot is
+	// freed once the consumer has handed its chunk to fn.
+	sem := make(chan struct{}, f.opts.Concurrency)
+	tasks := make(chan *logTask, f.opts.Concurrency)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(tasks)
+		for next := from; next <= to; {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			end := to
+			if size := f.size.Load(); to-next >= size {
+				end = next + size - 1
+			}
+			t := &logTask{chunk: LogChunk{From: next, To: end}, done: make(chan struct{})}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer close(t.done)
+				t.chunk.Logs, t.err = f.fetch(ctx, t.chunk.From, t.chunk.To)
+			}()
+			tasks <- t
+			if end == to {
+				return
+			}
+			next = end + 1
+		}
+	}()
+
+	for t := range tasks {
+		select {
+		case <-t.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if t.err != nil {
+			return t.err
+		}
+		if err := fn(t.chunk); err != nil {
+			return err
+		}
+		<-sem
+	}
+	return ctx.Err()
+}
+
+// fetch gets the logs of [from, to], halving the range while the provider
+// rejects it as too large.
+func (f *logFetcher) fetch(ctx context.Context, from, to uint64) ([]*Log, error) {
+	lo, hi := ethgo.BlockNumber(from), ethgo.BlockNumber(to)
+	var out []*Log
+	err := f.c.RawCallContext(ctx, "eth_getLogs", &out, filterParams(f.q, &lo, &hi))
+	if err == nil || from == to || !isLogLimit(err) {
+		return out, err
+	}
+
+	mid := from + (to-from)/2
+	for half := mid - from + 1; ; {
+		cur := f.size.Load()
+		if cur <= half || f.size.CompareAndSwap(cur, half) {
+			break
+		}
+	}
+	left, err := f.fetch(ctx, from, mid)
+	if err != nil {
+		return nil, err
+	}
+	right, err := f.fetch(ctx, mid+1, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+// Provider messages for eth_getLogs queries that are too large, beyond those
+// matched by isRangeLimit, lower-cased.
+var logLimitNeedles = []string{
+	"query returned more than", // geth, Infura, Erigon
+	"range is too large",
+	"range too large",
+	"exceed maximum block range", // Erigon, Reth
+	"max block range",
+	"too many blocks",
+	"is limited to a",            // QuickNode "eth_getLogs is limited to a 10,000 range"
+	"log response size exceeded", // Alchemy
+}
+
+// isLogLimit reports whether err rejects an eth_getLogs request for its
+// range or result size, so a smaller range may succeed.
+func isLogLimit(err error) bool {
+	if errors.Is(err, ErrRateLimited) {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusRequestEntityTooLarge
+	}
+	var obj *codec.ErrorObject
+	if !errors.As(err, &obj) {
+		return false
+	}
+	msg := obj.Message
+	if s, ok := obj.Data.(string); ok {
+		msg += " " + s
+	}
+	if isRangeLimit(msg) {
+		return true
+	}
+	msg = strings.ToLower(msg)
+	for _, n := range logLimitNeedles {
+		if strings.Contains(msg, n) {
+			return true
+		}
+	}
+	return false
+}
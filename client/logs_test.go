@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+// newLogServer serves one log per block and rejects eth_getLogs ranges wider
+// than maxRange the way geth-based providers do.
+func newLogServer(t *testing.T, head, maxRange uint64, inFlight, peak *atomic.Int64) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch req.Method {
+		case "eth_blockNumber":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":"0x%x"}`, req.ID, head)
+			return
+		case "eth_getLogs":
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+			return
+		}
+		if n := inFlight.Add(1); n > peak.Load() {
+			peak.Store(n)
+		}
+		defer inFlight.Add(-1)
+
+		var q struct{ FromBlock, ToBlock string }
+		require.NoError(t, json.Unmarshal(req.Params[0], &q))
+		from, to := mustU64(t, q.FromBlock), mustU64(t, q.ToBlock)
+		if to-from+1 > maxRange {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":{"code":-32005,"message":"query returned more than 10000 results"}}`, req.ID)
+			return
+		}
+		var logs []string
+		for n := from; n <= to; n++ {
+			logs = append(logs, fmt.Sprintf(`{"address":"0x%040x","topics":[],"data":"0x","blockNumber":"0x%x","transactionHash":"0x%064x","transactionIndex":"0x0","blockHash":"0x%064x","logIndex":"0x0","removed":false}`, 1, n, n, n))
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":[%s]}`, req.ID, strings.Join(logs, ","))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func mustU64(t *testing.T, s string) uint64 {
+	n, err := utils.StrToU64(s)
+	require.NoError(t, err)
+	return n
+}
+
+func TestStreamLogs(t *testing.T) {
+	var inFlight, peak atomic.Int64
+	srv := newLogServer(t, 999, 64, &inFlight, &peak)
+	cli, err := dial(srv.URL)
+	require.NoError(t, err)
+
+	from := ethgo.BlockNumber(0)
+	q := &FilterQuery{From: &from} // to latest
+	var chunks []LogChunk
+	err = cli.StreamLogs(context.Background(), q, &LogOptions{ChunkSize: 200, Concurrency: 3}, func(ch LogChunk) error {
+		chunks = append(chunks, ch)
+		return nil
+	})
+	require.NoError(t, err)
+
+	next := uint64(0)
+	for _, ch := range chunks {
+		require.Equal(t, next, ch.From, "chunks delivered in order without gaps")
+		require.Len(t, ch.Logs, int(ch.To-ch.From+1))
+		for i, l := range ch.Logs {
+			require.Equal(t, ch.From+uint64(i), l.BlockNumber)
+		}
+		next = ch.To + 1
+	}
+	require.Equal(t, uint64(1000), next)
+	require.LessOrEqual(t, peak.Load(), int64(3))
+	require.LessOrEqual(t, chunks[len(chunks)-1].To-chunks[len(chunks)-1].From+1, uint64(64), "later chunks start at the reduced size")
+
+	logs, err := cli.FetchLogs(q, &LogOptions{ChunkSize: 50})
+	require.NoError(t, err)
+	require.Len(t, logs, 1000)
+}
+
+func TestStreamLogsErrors(t *testing.T) {
+	var inFlight, peak atomic.Int64
+	srv := newLogServer(t, 999, 64, &inFlight, &peak)
+	cli, err := dial(srv.URL)
+	require.NoError(t, err)
+
+	from, to := ethgo.BlockNumber(0), ethgo.BlockNumber(499)
+	stop := errors.New("stop")
+	var got int
+	err = cli.StreamLogs(context.Background(), &FilterQuery{From: &from, To: &to}, &LogOptions{ChunkSize: 50}, func(ch LogChunk) error {
+		if got++; got == 2 {
+			return stop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, stop)
+	require.Equal(t, 2, got)
+
+	// A single block that still exceeds the limit is returned as-is.
+	srv = newLogServer(t, 999, 0, &inFlight, &peak)
+	cli, err = dial(srv.URL)
+	require.NoError(t, err)
+	_, err = cli.FetchLogs(&FilterQuery{From: &from, To: &to}, nil)
+	var obj *codec.ErrorObject
+	require.ErrorAs(t, err, &obj)
+	require.Equal(t, -32005, obj.Code)
+
+	require.True(t, isLogLimit(&codec.ErrorObject{Code: -32602, Message: "Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range"}))
+	require.True(t, isLogLimit(&codec.ErrorObject{Code: -32000, Message: "eth_getLogs is limited to a 10,000 range"}))
+	require.False(t, isLogLimit(wrapError(&codec.ErrorObject{Code: -32005, Message: "daily request count exceeded"})))
+	require.False(t, isLogLimit(&codec.ErrorObject{Code: -32000, Message: "invalid params"}))
+}
@@ -0,0 +1,436 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+var (
+	ErrNoEndpoints = errors.New("no endpoints")
+	ErrNoQuorum    = errors.New("endpoints did not reach quorum")
+)
+
+// ChainIDMismatchError is returned when an endpoint serves a different chain.
+type ChainIDMismatchError struct {
+	Endpoint string
+	Want     *big.Int
+	Got      *big.Int
+}
+
+func (e *ChainIDMismatchError) Error() string {
+	return fmt.Sprintf("endpoint %s: chain id %s, want %s", e.Endpoint, e.Got, e.Want)
+}
+
+// MultiOptions configures a multi-endpoint client.
+type MultiOptions struct {
+	// MaxLag is how many blocks an endpoint may trail the best known head
+	// before calls skip it (default 3).
+	MaxLag uint64
+	// Quorum is how many endpoints must return the same nonce/balance
+	// (0 or 1 -> no quorum; the healthiest endpoint answers).
+	Quorum int
+	// HealthInterval is how often heads are refreshed in the background
+	// (0 -> 15s, negative -> never; use Refresh).
+	HealthInterval time.Duration
+}
+
+// EndpointStatus is a snapshot of one endpoint's health.
+type EndpointStatus struct {
+	Endpoint string
+	Head     uint64
+	Latency  time.Duration
+	Healthy  bool
+	Err      error
+}
+
+type node struct {
+	endpoint string
+	c        *Client
+
+	verified bool // chain id matched
+	head     uint64
+	latency  time.Duration
+	err      error
+}
+
+type pool struct {
+	chainID *big.Int
+	opts    MultiOptions
+	nodes   []*node
+
+	mu   sync.Mutex
+	best uint64
+
+	running bool // background refresh started
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewMultiClient returns a Client backed by several endpoints of the same chain.
+//
+// Every endpoint must report the same chain id; unreachable endpoints are kept
+// and verified once they answer. Reads go to the healthiest endpoint that is
+// within MaxLag of the best head and fail over on transport errors; nonce and
+// balance reads need Quorum matching answers; raw transactions are sent to all
+// endpoints.
+func NewMultiClient(endpoints []string, opts *MultiOptions) (*Client, error) {
+	return NewMultiClientContext(context.Background(), endpoints, opts)
+}
+
+// NewMultiClientContext is NewMultiClient with a context for the startup checks.
+func NewMultiClientContext(ctx context.Context, endpoints []string, opts *MultiOptions) (*Client, error) {
+	if len(endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	p := &pool{stop: make(chan struct{}), done: make(chan struct{})}
+	if opts != nil {
+		p.opts = *opts
+	}
+	if p.opts.MaxLag == 0 {
+		p.opts.MaxLag = 3
+	}
+	if p.opts.HealthInterval == 0 {
+		p.opts.HealthInterval = 15 * time.Second
+	}
+
+	for _, ep := range endpoints {
+		c, err := dial(ep)
+		if err != nil {
+			p.close()
+			return nil, fmt.Errorf("endpoint %s: %w", ep, err)
+		}
+		p.nodes = append(p.nodes, &node{endpoint: ep, c: c})
+	}
+
+	ids := make([]*big.Int, len(p.nodes))
+	errs := make([]error, len(p.nodes))
+	p.each(func(i int, n *node) {
+		ids[i], errs[i] = n.c.ChainIDContext(ctx)
+	})
+	for i, n := range p.nodes {
+		if errs[i] != nil {
+			n.err = errs[i]
+			continue
+		}
+		if p.chainID == nil {
+			p.chainID = ids[i]
+		}
+		if ids[i].Cmp(p.chainID) != 0 {
+			p.close()
+			return nil, &ChainIDMismatchError{Endpoint: n.endpoint, Want: p.chainID, Got: ids[i]}
+		}
+		n.verified = true
+		n.c.ChainId = ids[i]
+	}
+	if p.chainID == nil {
+		p.close()
+		return nil, fmt.Errorf("no endpoint reachable: %w", errors.Join(errs...))
+	}
+
+	p.refresh(ctx)
+	if p.opts.HealthInterval > 0 {
+		p.running = true
+		go p.loop()
+	}
+
+	client := &Client{pool: p, ChainId: p.chainID}
+	client.NonceManager = NewNonceManager(client, 0)
+	return client, nil
+}
+
+// Refresh re-checks the head of every endpoint. It is a no-op for
+// single-endpoint clients.
+func (c *Client) Refresh(ctx context.Context) {
+	if c.pool != nil {
+		c.pool.refresh(ctx)
+	}
+}
+
+// Endpoints reports the health of every endpoint (nil for single-endpoint clients).
+func (c *Client) Endpoints() []EndpointStatus {
+	if c.pool == nil {
+		return nil
+	}
+	p := c.pool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]EndpointStatus, len(p.nodes))
+	for i, n := range p.nodes {
+		out[i] = EndpointStatus{
+			Endpoint: n.endpoint,
+			Head:     n.head,
+			Latency:  n.latency,
+			Healthy:  p.healthy(n),
+			Err:      n.err,
+		}
+	}
+	return out
+}
+
+/* ---------- Health ---------- */
+
+func (p *pool) refresh(ctx context.Context) {
+	p.each(func(_ int, n *node) {
+		p.mu.Lock()
+		verified := n.verified
+		p.mu.Unlock()
+
+		if !verified {
+			id, err := n.c.ChainIDContext(ctx)
+			if err == nil && id.Cmp(p.chainID) != 0 {
+				err = &ChainIDMismatchError{Endpoint: n.endpoint, Want: p.chainID, Got: id}
+			}
+			p.mu.Lock()
+			n.err = err
+			if err == nil {
+				n.c.ChainId = id
+			}
+			p.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+
+		start := time.Now()
+		head, err := n.c.BlockNumberContext(ctx)
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		n.verified = true
+		n.err = err
+		if err == nil {
+			n.head = head
+			n.latency = time.Since(start)
+			if head > p.best {
+				p.best = head
+			}
+		}
+	})
+}
+
+func (p *pool) loop() {
+	defer close(p.done)
+	t := time.NewTicker(p.opts.HealthInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-t.C:
+			ctx, cancel := context.WithTimeout(context.Background(), p.opts.HealthInterval)
+			p.refresh(ctx)
+			cancel()
+		}
+	}
+}
+
+func (p *pool) close() error {
+	select {
+	case <-p.stop:
+	default:
+		close(p.stop)
+	}
+	if p.running {
+		<-p.done
+	}
+	var errs []error
+	for _, n := range p.nodes {
+		errs = append(errs, n.c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// healthy must be called with p.mu held.
+func (p *pool) healthy(n *node) bool {
+	return n.verified && n.err == nil && n.head+p.opts.MaxLag >= p.best
+}
+
+// candidates returns verified endpoints, healthiest first: healthy before
+// unhealthy, then by lag, then by latency.
+func (p *pool) candidates() []*node {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out []*node
+	for _, n := range p.nodes {
+		if n.verified {
+			out = append(out, n)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if ha, hb := p.healthy(a), p.healthy(b); ha != hb {
+			return ha
+		}
+		if a.head != b.head {
+			return a.head > b.head
+		}
+		return a.latency < b.latency
+	})
+	return out
+}
+
+func (p *pool) markFailed(n *node, err error) {
+	p.mu.Lock()
+	n.err = err
+	p.mu.Unlock()
+}
+
+func (p *pool) each(fn func(i int, n *node)) {
+	var wg sync.WaitGroup
+	for i, n := range p.nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(i, n)
+		}()
+	}
+	wg.Wait()
+}
+
+/* ---------- Calls ---------- */
+
+// failover reports whether err is an endpoint problem worth retrying elsewhere.
+// JSON-RPC errors are answers from the chain (reverts, bad params) and are
+// returned as-is, except rate limits.
+func failover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	var rpcErr *codec.ErrorObject
+	return !errors.As(err, &rpcErr)
+}
+
+func (p *pool) call(ctx context.Context, method string, out any, params ...any) error {
+	var errs []error
+	for _, n := range p.candidates() {
+		err := n.c.RawCallContext(ctx, method, out, params...)
+		if err == nil || !failover(ctx, err) {
+			return err
+		}
+		p.markFailed(n, err)
+		errs = append(errs, fmt.Errorf("%s: %w", n.endpoint, err))
+	}
+	if len(errs) == 0 {
+		return ErrNoEndpoints
+	}
+	return errors.Join(errs...)
+}
+
+// quorumCall asks every healthy endpoint and decodes the first result returned
+// by at least opts.Quorum of them.
+func (p *pool) quorumCall(ctx context.Context, method string, out any, params ...any) error {
+	var nodes []*node
+	for _, n := range p.candidates() {
+		p.mu.Lock()
+		ok := p.healthy(n)
+		p.mu.Unlock()
+		if ok {
+			nodes = append(nodes, n)
+		}
+	}
+	if len(nodes) < p.opts.Quorum {
+		return fmt.Errorf("%w: %d healthy endpoints, need %d", ErrNoQuorum, len(nodes), p.opts.Quorum)
+	}
+
+	type answer struct {
+		raw json.RawMessage
+		err error
+	}
+	ch := make(chan answer, len(nodes))
+	for _, n := range nodes {
+		go func() {
+			var raw json.RawMessage
+			err := n.c.RawCallContext(ctx, method, &raw, params...)
+			if err != nil && failover(ctx, err) {
+				p.markFailed(n, err)
+			}
+			ch <- answer{raw, err}
+		}()
+	}
+
+	var seen []json.RawMessage
+	var counts []int
+	var errs []error
+	for range nodes {
+		a := <-ch
+		if a.err != nil {
+			errs = append(errs, a.err)
+			continue
+		}
+		i := 0
+		for ; i < len(seen) && !bytes.Equal(seen[i], a.raw); i++ {
+		}
+		if i == len(seen) {
+			seen = append(seen, a.raw)
+			counts = append(counts, 0)
+		}
+		if counts[i]++; counts[i] >= p.opts.Quorum {
+			return json.Unmarshal(a.raw, out)
+		}
+	}
+	return fmt.Errorf("%w: %d distinct answers, %d errors: %v", ErrNoQuorum, len(seen), len(errs), errors.Join(errs...))
+}
+
+// broadcast sends the call to every verified endpoint concurrently and
+// returns the first successful result; the remaining sends keep running until
+// ctx's deadline even after ctx is cancelled. If all fail, the error from the
+// healthiest endpoint is returned.
+func (p *pool) broadcast(ctx context.Context, method string, out any, params ...any) error {
+	nodes := p.candidates()
+	if len(nodes) == 0 {
+		return ErrNoEndpoints
+	}
+	bctx, cancel := context.WithoutCancel(ctx), context.CancelFunc(func() {})
+	if deadline, ok := ctx.Deadline(); ok {
+		bctx, cancel = context.WithDeadline(bctx, deadline)
+	}
+
+	type answer struct {
+		i   int
+		raw json.RawMessage
+		err error
+	}
+	ch := make(chan answer, len(nodes))
+	var wg sync.WaitGroup
+	for i, n := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var raw json.RawMessage
+			err := n.c.RawCallContext(bctx, method, &raw, params...)
+			if err != nil && failover(bctx, err) {
+				p.markFailed(n, err)
+			}
+			ch <- answer{i, raw, err}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+	}()
+
+	errs := make([]error, len(nodes))
+	for range nodes {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case a := <-ch:
+			if a.err == nil {
+				return json.Unmarshal(a.raw, out)
+			}
+			errs[a.i] = a.err
+		}
+	}
+	return errs[0]
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+)
+
+func TestMultiClient(t *testing.T) {
+	const addr = "0x0000000000000000000000000000000000000001"
+	a := newTestServer(t, map[string]string{
+		"eth_chainId": `"0x1"`, "eth_blockNumber": `"0x64"`,
+		"eth_getBalance": `"0x10"`, "eth_getTransactionCount": `"0x5"`,
+		"eth_sendRawTransaction": `"0x0000000000000000000000000000000000000000000000000000000000000001"`,
+	})
+	b := newTestServer(t, map[string]string{
+		"eth_chainId": `"0x1"`, "eth_blockNumber": `"0x64"`,
+		"eth_getBalance": `"0x10"`, "eth_getTransactionCount": `"0x6"`,
+		"eth_sendRawTransaction": `{"code":-32000,"message":"already known"}`,
+	})
+	lagging := newTestServer(t, map[string]string{
+		"eth_chainId": `"0x1"`, "eth_blockNumber": `"0x0a"`,
+		"eth_getBalance":         `"0x99"`,
+		"eth_sendRawTransaction": `{"code":-32000,"message":"nonce too low"}`,
+	})
+	other := newTestServer(t, map[string]string{"eth_chainId": `"0x5"`})
+
+	_, err := NewMultiClient([]string{a.URL, other.URL}, nil)
+	var mismatch *ChainIDMismatchError
+	require.True(t, errors.As(err, &mismatch), err)
+
+	cli, err := NewMultiClient([]string{lagging.URL, a.URL, b.URL}, &MultiOptions{Quorum: 2, HealthInterval: -1})
+	require.NoError(t, err)
+	defer cli.Close()
+
+	st := cli.Endpoints()
+	require.False(t, st[0].Healthy)
+	require.True(t, st[1].Healthy)
+
+	// The lagging endpoint is excluded from the quorum.
+	bal, err := cli.BalanceAt(addr, ethgo.Latest)
+	require.NoError(t, err)
+	require.Equal(t, int64(0x10), bal.Int64())
+
+	_, err = cli.NonceAt(addr, ethgo.Latest)
+	require.ErrorIs(t, err, ErrNoQuorum)
+
+	// Only one endpoint accepts the tx; the broadcast still succeeds.
+	h, err := cli.SendRawTransaction([]byte{0x01})
+	require.NoError(t, err)
+	require.Equal(t, byte(1), h[31])
+}
@@ -1,8 +1,8 @@
 package client
 
 import (
+	"context"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
@@ -37,13 +37,18 @@ func NewNonceManager(c *Client, ttl time.Duration) *NonceManager {
 }
 
 func (m *NonceManager) Next(addr string) (uint64, error) {
+	return m.NextContext(context.Background(), addr)
+}
+
+// NextContext is Next with a context; ctx bounds the sync when one is needed.
+func (m *NonceManager) NextContext(ctx context.Context, addr string) (uint64, error) {
 	m.mu.Lock()
 	e := m.cache[addr]
 	needSync := e == nil || (m.ttl > 0 && time.Since(e.lastSyncAt) > m.ttl)
 	m.mu.Unlock()
 
 	if needSync {
-		if err := m.Sync(addr); err != nil {
+		if err := m.SyncContext(ctx, addr); err != nil {
 			return 0, err
 		}
 	}
@@ -57,11 +62,16 @@ func (m *NonceManager) Next(addr string) (uint64, error) {
 }
 
 func (m *NonceManager) Sync(addr string) error {
-	pendingNonce, err := m.pendingNonce(addr)
+	return m.SyncContext(context.Background(), addr)
+}
+
+// SyncContext is Sync with a context.
+func (m *NonceManager) SyncContext(ctx context.Context, addr string) error {
+	pendingNonce, err := m.pendingNonce(ctx, addr)
 	if err != nil {
 		return err
 	}
-	latestBlock, _ := m.c.BlockNumber()
+	latestBlock, _ := m.c.BlockNumberContext(ctx)
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -80,15 +90,8 @@ func (m *NonceManager) OnSendError(addr string, err error) (refreshed bool, _ er
 	if err == nil {
 		return false, nil
 	}
-	msg := strings.ToLower(err.Error())
-
-	tooLow := strings.Contains(msg, "nonce too low") ||
-		strings.Contains(msg, "already known") ||
-		strings.Contains(msg, "replacement transaction underpriced")
-	tooHigh := strings.Contains(msg, "nonce too high") ||
-		strings.Contains(msg, "transaction nonce is too high")
-
-	if tooLow || tooHigh {
+	switch Classify(err) {
+	case ErrNonceTooLow, ErrAlreadyKnown, ErrReplacementUnderpriced, ErrNonceTooHigh:
 		return true, m.Sync(addr)
 	}
 	return false, nil
@@ -117,14 +120,16 @@ func (m *NonceManager) must(addr string) *entry {
 }
 
 // pendingNonce tries the typed call first; falls back to raw RPC if needed.
-func (m *NonceManager) pendingNonce(addr string) (uint64, error) {
-	if n, err := m.c.NonceAt(addr, ethgo.BlockNumber(ethgo.Pending)); err == nil {
+func (m *NonceManager) pendingNonce(ctx context.Context, addr string) (uint64, error) {
+	if n, err := m.c.NonceAtContext(ctx, addr, ethgo.BlockNumber(ethgo.Pending)); err == nil {
 		return n, nil
+	} else if ctx.Err() != nil {
+		return 0, err
 	}
 
 	// 2) raw RPC fallback
 	var out string
-	if err := m.c.rpc.Call("eth_getTransactionCount", &out, addr, "pending"); err != nil {
+	if err := m.c.RawCallContext(ctx, "eth_getTransactionCount", &out, addr, "pending"); err != nil {
 		return 0, fmt.Errorf("pending nonce fetch failed: %w", err)
 	}
 	return utils.StrToU64(out)
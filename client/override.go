@@ -0,0 +1,238 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+)
+
+// ErrStateConflict is returned for an account override with both State and
+// StateDiff set.
+var ErrStateConflict = errors.New("state and stateDiff overrides are mutually exclusive")
+
+// AccountOverride replaces parts of one account's state for a call. State
+// replaces the whole storage; StateDiff patches single slots. Only one of
+// the two may be set.
+type AccountOverride struct {
+	Balance   *big.Int
+	Nonce     *uint64
+	Code      []byte
+	State     map[ethgo.Hash]ethgo.Hash
+	StateDiff map[ethgo.Hash]ethgo.Hash
+	// MovePrecompileTo relocates the precompile at this address (geth,
+	// eth_simulateV1).
+	MovePrecompileTo *ethgo.Address
+}
+
+func (a *AccountOverride) MarshalJSON() ([]byte, error) {
+	if a.State != nil && a.StateDiff != nil {
+		return nil, ErrStateConflict
+	}
+	out := map[string]any{}
+	if a.Balance != nil {
+		out["balance"] = fmt.Sprintf("0x%x", a.Balance)
+	}
+	if a.Nonce != nil {
+		out["nonce"] = fmt.Sprintf("0x%x", *a.Nonce)
+	}
+	if a.Code != nil {
+		out["code"] = fmt.Sprintf("0x%x", a.Code)
+	}
+	if a.State != nil {
+		out["state"] = slotMap(a.State)
+	}
+	if a.StateDiff != nil {
+		out["stateDiff"] = slotMap(a.StateDiff)
+	}
+	if a.MovePrecompileTo != nil {
+		out["movePrecompileToAddress"] = a.MovePrecompileTo.String()
+	}
+	return json.Marshal(out)
+}
+
+func slotMap(m map[ethgo.Hash]ethgo.Hash) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k.String()] = v.String()
+	}
+	return out
+}
+
+// StateOverride maps accounts to their overrides. Build one with
+// NewStateOverride and the chaining setters:
+//
+//	ov := client.NewStateOverride().
+//		Balance(alice, ether).
+//		StateDiff(token, slot, value)
+type StateOverride map[ethgo.Address]*AccountOverride
+
+func NewStateOverride() StateOverride {
+	return StateOverride{}
+}
+
+// Validate reports the first account with both State and StateDiff set.
+// The override calls run it before sending.
+func (s StateOverride) Validate() error {
+	for addr, a := range s {
+		if a != nil && a.State != nil && a.StateDiff != nil {
+			return fmt.Errorf("override for %s: %w", addr, ErrStateConflict)
+		}
+	}
+	return nil
+}
+
+func (s StateOverride) account(addr ethgo.Address) *AccountOverride {
+	a := s[addr]
+	if a == nil {
+		a = &AccountOverride{}
+		s[addr] = a
+	}
+	return a
+}
+
+// Balance sets addr's balance in wei.
+func (s StateOverride) Balance(addr ethgo.Address, wei *big.Int) StateOverride {
+	s.account(addr).Balance = wei
+	return s
+}
+
+// Nonce sets addr's nonce.
+func (s StateOverride) Nonce(addr ethgo.Address, nonce uint64) StateOverride {
+	s.account(addr).Nonce = &nonce
+	return s
+}
+
+// Code sets addr's runtime bytecode.
+func (s StateOverride) Code(addr ethgo.Address, code []byte) StateOverride {
+	s.account(addr).Code = code
+	return s
+}
+
+// State sets one slot of addr's storage and clears all others. It cannot be
+// combined with StateDiff on the same account (see Validate).
+func (s StateOverride) State(addr ethgo.Address, slot, value ethgo.Hash) StateOverride {
+	a := s.account(addr)
+	if a.State == nil {
+		a.State = map[ethgo.Hash]ethgo.Hash{}
+	}
+	a.State[slot] = value
+	return s
+}
+
+// StateDiff sets one slot of addr's storage and keeps the others. It cannot
+// be combined with State on the same account (see Validate).
+func (s StateOverride) StateDiff(addr ethgo.Address, slot, value ethgo.Hash) StateOverride {
+	a := s.account(addr)
+	if a.StateDiff == nil {
+		a.StateDiff = map[ethgo.Hash]ethgo.Hash{}
+	}
+	a.StateDiff[slot] = value
+	return s
+}
+
+// MovePrecompile relocates the precompile at addr to dest.
+func (s StateOverride) MovePrecompile(addr, dest ethgo.Address) StateOverride {
+	s.account(addr).MovePrecompileTo = &dest
+	return s
+}
+
+// BlockOverride replaces header fields of the block a call runs in. Nil
+// fields keep the block's values.
+type BlockOverride struct {
+	Number       *big.Int
+	Time         *uint64
+	GasLimit     *uint64
+	FeeRecipient *ethgo.Address
+	PrevRandao   *ethgo.Hash
+	BaseFee      *big.Int
+	BlobBaseFee  *big.Int
+}
+
+func (b *BlockOverride) MarshalJSON() ([]byte, error) {
+	out := map[string]any{}
+	if b.Number != nil {
+		out["number"] = fmt.Sprintf("0x%x", b.Number)
+	}
+	if b.Time != nil {
+		out["time"] = fmt.Sprintf("0x%x", *b.Time)
+	}
+	if b.GasLimit != nil {
+		out["gasLimit"] = fmt.Sprintf("0x%x", *b.GasLimit)
+	}
+	if b.FeeRecipient != nil {
+		out["feeRecipient"] = b.FeeRecipient.String()
+	}
+	if b.PrevRandao != nil {
+		out["prevRandao"] = b.PrevRandao.String()
+	}
+	if b.BaseFee != nil {
+		out["baseFeePerGas"] = fmt.Sprintf("0x%x", b.BaseFee)
+	}
+	if b.BlobBaseFee != nil {
+		out["blobBaseFee"] = fmt.Sprintf("0x%x", b.BlobBaseFee)
+	}
+	return json.Marshal(out)
+}
+
+/* ---------- Calls with overrides ---------- */
+
+// CallWithOverrides is Call with state and block overrides; either may be nil.
+func (c *Client) CallWithOverrides(msg *CallMsg, block ethgo.BlockNumber, state StateOverride, blk *BlockOverride) (string, error) {
+	return c.CallWithOverridesContext(context.Background(), msg, block, state, blk)
+}
+
+// CallWithOverridesContext is CallWithOverrides with a context.
+func (c *Client) CallWithOverridesContext(ctx context.Context, msg *CallMsg, block ethgo.BlockNumber, state StateOverride, blk *BlockOverride) (string, error) {
+	params, err := overrideParams(msg, block, state, blk)
+	if err != nil {
+		return "", err
+	}
+	var out string
+	if err := c.RawCallContext(ctx, "eth_call", &out, params...); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// EstimateGasWithOverrides is EstimateGas at block with state and block
+// overrides; either may be nil. Block overrides need a recent geth.
+func (c *Client) EstimateGasWithOverrides(msg *CallMsg, block ethgo.BlockNumber, state StateOverride, blk *BlockOverride) (uint64, error) {
+	return c.EstimateGasWithOverridesContext(context.Background(), msg, block, state, blk)
+}
+
+// EstimateGasWithOverridesContext is EstimateGasWithOverrides with a context.
+func (c *Client) EstimateGasWithOverridesContext(ctx context.Context, msg *CallMsg, block ethgo.BlockNumber, state StateOverride, blk *BlockOverride) (uint64, error) {
+	params, err := overrideParams(msg, block, state, blk)
+	if err != nil {
+		return 0, err
+	}
+	var out string
+	if err := c.RawCallContext(ctx, "eth_estimateGas", &out, params...); err != nil {
+		return 0, err
+	}
+	return utils.StrToU64(out)
+}
+
+// overrideParams validates state and drops trailing empty overrides so
+// nodes without override support still accept plain calls.
+func overrideParams(msg *CallMsg, block ethgo.BlockNumber, state StateOverride, blk *BlockOverride) ([]any, error) {
+	if err := state.Validate(); err != nil {
+		return nil, err
+	}
+	params := []any{msg, block.String()}
+	if state != nil || blk != nil {
+		if state == nil {
+			state = StateOverride{}
+		}
+		params = append(params, state)
+	}
+	if blk != nil {
+		params = append(params, blk)
+	}
+	return params, nil
+}
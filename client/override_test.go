@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+)
+
+func TestCallWithOverrides(t *testing.T) {
+	var params []json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		params = req.Params
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":"0x5208"}`, req.ID)
+	}))
+	defer srv.Close()
+
+	cli, err := dial(srv.URL)
+	require.NoError(t, err)
+
+	alice := ethgo.HexToAddress("0x0000000000000000000000000000000000000001")
+	token := ethgo.HexToAddress("0x0000000000000000000000000000000000000002")
+	state := NewStateOverride().
+		Balance(alice, big.NewInt(1e18)).
+		Nonce(alice, 7).
+		Code(token, []byte{0x60, 0x00}).
+		StateDiff(token, ethgo.Hash{31: 1}, ethgo.Hash{31: 0xff})
+	fee := big.NewInt(0)
+	msg := &CallMsg{From: alice, To: &token}
+
+	_, err = cli.CallWithOverrides(msg, ethgo.Latest, state, &BlockOverride{BaseFee: fee})
+	require.NoError(t, err)
+	require.Len(t, params, 4)
+	var got map[string]map[string]any
+	require.NoError(t, json.Unmarshal(params[2], &got))
+	require.Equal(t, "0xde0b6b3a7640000", got[alice.String()]["balance"])
+	require.Equal(t, "0x7", got[alice.String()]["nonce"])
+	require.Equal(t, "0x6000", got[token.String()]["code"])
+	require.Equal(t, map[string]any{ethgo.Hash{31: 1}.String(): ethgo.Hash{31: 0xff}.String()}, got[token.String()]["stateDiff"])
+	require.JSONEq(t, `{"baseFeePerGas":"0x0"}`, string(params[3]))
+
+	gas, err := cli.EstimateGasWithOverrides(msg, ethgo.Pending, state, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(21000), gas)
+	require.Len(t, params, 3)
+	require.JSONEq(t, `"pending"`, string(params[1]))
+
+	// Plain calls stay override-free for nodes that reject the extra params.
+	_, err = cli.CallWithOverrides(msg, ethgo.Latest, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, params, 2)
+
+	// State and StateDiff on one account are rejected before sending.
+	params = nil
+	conflict := NewStateOverride().State(token, ethgo.Hash{}, ethgo.Hash{}).StateDiff(token, ethgo.Hash{}, ethgo.Hash{})
+	require.ErrorIs(t, conflict.Validate(), ErrStateConflict)
+	_, err = cli.CallWithOverrides(msg, ethgo.Latest, conflict, nil)
+	require.ErrorIs(t, err, ErrStateConflict)
+	require.ErrorContains(t, err, token.String())
+	_, err = cli.EstimateGasWithOverrides(msg, ethgo.Latest, conflict, nil)
+	require.ErrorIs(t, err, ErrStateConflict)
+	require.Nil(t, params)
+}
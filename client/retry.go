@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// RetryPolicy configures retries with exponential, jittered backoff.
+// Zero fields take the defaults noted below.
+type RetryPolicy struct {
+	MaxAttempts    int           // total attempts including the first (default 3)
+	InitialBackoff time.Duration // default 200ms
+	MaxBackoff     time.Duration // default 5s
+	Multiplier     float64       // default 2
+	Jitter         float64       // fraction of each delay that is randomised, 0..1 (default 0.5)
+}
+
+// DefaultRetryPolicy is a conservative policy suitable for public endpoints.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.5,
+}
+
+// nonIdempotent lists methods whose effect may have happened even when the
+// call failed in transit; they are retried only when the node provably
+// rejected the request (rate limiting).
+var nonIdempotent = map[string]bool{
+	"eth_sendRawTransaction":            true,
+	"eth_sendTransaction":               true,
+	"eth_sendRawTransactionConditional": true,
+}
+
+// Retryable reports whether a failed call to method is safe to repeat.
+//
+// Rate limits (including HTTP 429) are always retryable. Transport failures
+// (network errors, connections closed mid-response) and 5xx responses are
+// retryable for read methods only. Everything else is an answer, not a
+// failure, and is never retried: JSON-RPC errors (reverts, nonce and fee
+// errors), other 4xx responses and results that do not decode.
+func Retryable(method string, err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if nonIdempotent[method] {
+		return false
+	}
+	if httpErr != nil {
+		return httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	return isTransportError(err)
+}
+
+// isTransportError reports whether err is a network failure rather than a
+// response.
+func isTransportError(err error) bool {
+	var netErr net.Error
+	var closeErr *websocket.CloseError
+	return errors.As(err, &netErr) || errors.As(err, &closeErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func (p *RetryPolicy) withDefaults() RetryPolicy {
+	r := *p
+	if r.MaxAttempts <= 0 {
+		r.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if r.InitialBackoff <= 0 {
+		r.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+	}
+	if r.MaxBackoff <= 0 {
+		r.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+	if r.Multiplier < 1 {
+		r.Multiplier = DefaultRetryPolicy.Multiplier
+	}
+	if r.Jitter <= 0 || r.Jitter > 1 {
+		r.Jitter = DefaultRetryPolicy.Jitter
+	}
+	return r
+}
+
+// backoff returns the delay before retry number attempt (1-based).
+func (p *RetryPolicy) backoff(attempt int, err error) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= p.Multiplier
+	}
+	d = min(d, float64(p.MaxBackoff))
+	d = d*(1-p.Jitter) + rand.Float64()*d*p.Jitter
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.RetryAfter > time.Duration(d) {
+		return min(httpErr.RetryAfter, p.MaxBackoff)
+	}
+	return time.Duration(d)
+}
+
+// retry runs fn until it succeeds, fails with a non-retryable error, runs
+// out of attempts or ctx is done.
+func (p *RetryPolicy) retry(ctx context.Context, method string, fn func() error) error {
+	pol := p.withDefaults()
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= pol.MaxAttempts || !Retryable(method, err) {
+			return err
+		}
+		t := time.NewTimer(pol.backoff(attempt, err))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
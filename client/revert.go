@@ -0,0 +1,336 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo/abi"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+var (
+	// errorSelector is the selector of Error(string), the standard revert reason.
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+	// panicSelector is the selector of Panic(uint256), used by Solidity >= 0.8.
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+)
+
+// PanicReasons maps Solidity panic codes to their meaning.
+var PanicReasons = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array encoding",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to zero-initialized internal function",
+}
+
+// RevertError is a call that reverted, with its revert data decoded where
+// possible. It matches ErrExecutionReverted with errors.Is, and the node's
+// original error stays reachable through errors.As.
+type RevertError struct {
+	Data []byte // raw revert data; empty if the node sent none
+
+	// Reason is the Error(string) message, or the node's reason text when
+	// no data was sent.
+	Reason string
+	// Panic is the Panic(uint256) code.
+	Panic *big.Int
+	// Custom is the signature of a registered custom error, e.g.
+	// "ERC20InsufficientBalance(address,uint256,uint256)", and Args its
+	// decoded arguments by name (or position when unnamed).
+	Custom string
+	Args   map[string]any
+
+	Err error // the node error the revert was extracted from, if any
+}
+
+func (e *RevertError) Error() string {
+	var msg string
+	switch {
+	case e.Panic != nil:
+		text := "unknown panic code"
+		if e.Panic.IsUint64() {
+			if r, ok := PanicReasons[e.Panic.Uint64()]; ok {
+				text = r
+			}
+		}
+		msg = fmt.Sprintf("panic: %s (0x%x)", text, e.Panic)
+	case e.Custom != "":
+		msg = e.formatCustom()
+	case e.Reason != "":
+		msg = e.Reason
+	case len(e.Data) > 0:
+		msg = fmt.Sprintf("0x%x", e.Data)
+	default:
+		return "execution reverted"
+	}
+	return "execution reverted: " + msg
+}
+
+// formatCustom renders Name(arg=value, ...) in declaration order.
+func (e *RevertError) formatCustom() string {
+	name := e.Custom[:strings.IndexByte(e.Custom, '(')]
+	var args []string
+	if ce := lookupError(e.Data); ce != nil {
+		for i, el := range ce.Inputs.TupleElems() {
+			key := argKey(el.Name, i)
+			args = append(args, fmt.Sprintf("%s=%v", key, e.Args[key]))
+		}
+	} else {
+		keys := make([]string, 0, len(e.Args))
+		for k := range e.Args {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			args = append(args, fmt.Sprintf("%s=%v", k, e.Args[k]))
+		}
+	}
+	return name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// decoded reports whether Data was recognised as Error, Panic or a
+// registered custom error.
+func (e *RevertError) decoded() bool {
+	return e.Reason != "" || e.Panic != nil || e.Custom != ""
+}
+
+func (e *RevertError) Is(target error) bool { return target == ErrExecutionReverted }
+
+func (e *RevertError) Unwrap() error { return e.Err }
+
+/* ---------- Custom error registry ---------- */
+
+var (
+	errorsMu     sync.RWMutex
+	customErrors = map[[4]byte]*abi.Error{}
+)
+
+// RegisterErrors makes the custom errors of a (e.g. an erc20 runtime's
+// ABI()) decodable by DecodeRevert.
+func RegisterErrors(a *abi.ABI) {
+	errorsMu.Lock()
+	defer errorsMu.Unlock()
+	for _, e := range a.Errors {
+		customErrors[errorID(e)] = e
+	}
+}
+
+// RegisterError registers one custom error by signature, e.g.
+// "InsufficientBalance(uint256 available, uint256 required)".
+func RegisterError(signature string) error {
+	e, err := abi.NewError("error " + signature)
+	if err != nil {
+		return err
+	}
+	errorsMu.Lock()
+	customErrors[errorID(e)] = e
+	errorsMu.Unlock()
+	return nil
+}
+
+// errorSignature returns the canonical signature, e.g. "Name(address,uint256)".
+func errorSignature(e *abi.Error) string {
+	return e.Name + strings.TrimPrefix(e.Inputs.String(), "tuple")
+}
+
+func errorID(e *abi.Error) [4]byte {
+	var id [4]byte
+	copy(id[:], utils.Keccak([]byte(errorSignature(e))))
+	return id
+}
+
+func lookupError(data []byte) *abi.Error {
+	if len(data) < 4 {
+		return nil
+	}
+	var id [4]byte
+	copy(id[:], data)
+	errorsMu.RLock()
+	defer errorsMu.RUnlock()
+	return customErrors[id]
+}
+
+func argKey(name string, i int) string {
+	if name == "" {
+		return fmt.Sprint(i)
+	}
+	return name
+}
+
+/* ---------- Decoding ---------- */
+
+// DecodeRevert decodes revert data returned by a call.
+func DecodeRevert(data []byte) *RevertError {
+	e := &RevertError{Data: data}
+	switch {
+	case len(data) < 4:
+	case bytes.Equal(data[:4], errorSelector):
+		if reason, ok := unpackErrorString(data); ok {
+			e.Reason = reason
+		}
+	case bytes.Equal(data[:4], panicSelector):
+		if len(data) == 4+32 {
+			e.Panic = new(big.Int).SetBytes(data[4:])
+		}
+	default:
+		ce := lookupError(data)
+		if ce == nil {
+			break
+		}
+		v, err := abi.Decode(ce.Inputs, data[4:])
+		if err != nil {
+			break
+		}
+		e.Custom = errorSignature(ce)
+		e.Args = map[string]any{}
+		if m, ok := v.(map[string]any); ok {
+			for i, el := range ce.Inputs.TupleElems() {
+				if val, ok := m[el.Name]; ok {
+					e.Args[argKey(el.Name, i)] = val
+				} else if val, ok := m[fmt.Sprint(i)]; ok {
+					e.Args[argKey(el.Name, i)] = val
+				}
+			}
+		}
+	}
+	return e
+}
+
+// RevertFromError extracts a RevertError from a node error, or returns nil
+// if err is not a revert. It understands revert data in the error's data
+// field (geth, Erigon, Reth, Besu, Nethermind, Anvil), nested data objects
+// (Hardhat, Ganache) and messages that are only a prefix and the data
+// (Nethermind's "Reverted 0x...").
+func RevertFromError(err error) *RevertError {
+	if err == nil {
+		return nil
+	}
+	var rev *RevertError
+	if errors.As(err, &rev) {
+		return rev
+	}
+	if Classify(err) != ErrExecutionReverted {
+		return nil
+	}
+
+	msg := err.Error()
+	var data []byte
+	var obj *codec.ErrorObject
+	if errors.As(err, &obj) {
+		msg = obj.Message
+		data = findRevertData(obj.Data)
+	}
+	if data == nil {
+		data = messageData(msg)
+	}
+	e := DecodeRevert(data)
+	if !e.decoded() && len(data) == 0 {
+		e.Reason = messageReason(msg)
+	}
+	e.Err = err
+	return e
+}
+
+// findRevertData digs revert bytes out of an error data payload.
+func findRevertData(v any) []byte {
+	switch d := v.(type) {
+	case string:
+		if b := hexBytes(d); b != nil {
+			return b
+		}
+		return messageData(d)
+	case map[string]any:
+		for _, key := range []string{"data", "result", "return", "returnData", "originalError"} {
+			if b := findRevertData(d[key]); b != nil {
+				return b
+			}
+		}
+		for _, inner := range d { // Ganache: {"0x<txhash>": {"return": "0x..."}}
+			if m, ok := inner.(map[string]any); ok {
+				if b := findRevertData(m); b != nil {
+					return b
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// messageData returns the revert data of a message whose whole payload
+// after the revert prefix is hex, e.g. Nethermind's "Reverted 0x...". Hex
+// inside a reason ("AccessControl: account 0x... is missing role 0x...")
+// is not data, so the payload must also decode as Error, Panic or a
+// registered custom error.
+func messageData(msg string) []byte {
+	payload := msg
+	if i := strings.Index(strings.ToLower(msg), "reverted"); i >= 0 {
+		payload = msg[i+len("reverted"):]
+	}
+	payload = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(payload), ":"))
+	b := hexBytes(payload)
+	if b == nil || !DecodeRevert(b).decoded() {
+		return nil
+	}
+	return b
+}
+
+// hexBytes decodes s if it is entirely 0x-prefixed hex, or returns nil.
+func hexBytes(s string) []byte {
+	s = strings.TrimSpace(s)
+	if len(s) <= 2 || len(s)%2 != 0 || !strings.HasPrefix(s, "0x") {
+		return nil
+	}
+	b, err := utils.FromHex(s)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// messageReason returns the reason text from "execution reverted: reason".
+func messageReason(msg string) string {
+	if i := strings.Index(strings.ToLower(msg), "reverted:"); i >= 0 {
+		return strings.TrimSpace(msg[i+len("reverted:"):])
+	}
+	return ""
+}
+
+// unpackErrorString decodes Error(string) revert data.
+func unpackErrorString(data []byte) (string, bool) {
+	if len(data) < 4+64 || !bytes.Equal(data[:4], errorSelector) {
+		return "", false
+	}
+	body := data[4:]
+	off := readWord(body[:32])
+	if off > uint64(len(body))-32 {
+		return "", false
+	}
+	n := readWord(body[off : off+32])
+	if n > uint64(len(body))-off-32 {
+		return "", false
+	}
+	return string(body[off+32 : off+32+n]), true
+}
+
+// readWord returns a 32-byte ABI word as uint64, or MaxUint64 if it does not fit.
+func readWord(w []byte) uint64 {
+	for _, b := range w[:24] {
+		if b != 0 {
+			return ^uint64(0)
+		}
+	}
+	return binary.BigEndian.Uint64(w[24:])
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+func word(v uint64) string { return fmt.Sprintf("%064x", v) }
+
+func TestDecodeRevert(t *testing.T) {
+	reason := "0x08c379a0" + word(32) + word(4) + fmt.Sprintf("%x", "boom") + strings.Repeat("00", 28)
+	panicData := "0x4e487b71" + word(0x11)
+
+	require.NoError(t, RegisterError("InsufficientBalance(address account, uint256 balance, uint256 needed)"))
+	custom := "0x" + fmt.Sprintf("%x", utils.Keccak([]byte("InsufficientBalance(address,uint256,uint256)"))[:4]) +
+		strings.Repeat("0", 24) + strings.Repeat("ab", 20) + word(5) + word(7)
+
+	for _, test := range []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"geth data string", &codec.ErrorObject{Code: 3, Message: "execution reverted: boom", Data: reason}, "execution reverted: boom"},
+		{"panic", &codec.ErrorObject{Code: 3, Message: "execution reverted", Data: panicData}, "execution reverted: panic: arithmetic underflow or overflow (0x11)"},
+		{"custom", &codec.ErrorObject{Code: 3, Message: "execution reverted", Data: custom}, "execution reverted: InsufficientBalance(account=0xABaBaBaBABabABabAbAbABAbABabababaBaBABaB, balance=5, needed=7)"},
+		{"hardhat object", &codec.ErrorObject{Code: -32603, Message: "Error: VM Exception while processing transaction: reverted with panic code 0x11", Data: map[string]any{"message": "reverted", "data": panicData}}, "execution reverted: panic: arithmetic underflow or overflow (0x11)"},
+		{"ganache object", &codec.ErrorObject{Code: -32000, Message: "VM Exception while processing transaction: revert", Data: map[string]any{"0x1234": map[string]any{"error": "revert", "return": reason}}}, "execution reverted: boom"},
+		{"nethermind message", &codec.ErrorObject{Code: -32015, Message: "Reverted " + panicData}, "execution reverted: panic: arithmetic underflow or overflow (0x11)"},
+		{"reason only", &codec.ErrorObject{Code: -32000, Message: "execution reverted: Ownable: caller is not the owner"}, "execution reverted: Ownable: caller is not the owner"},
+		{"reason with hex", &codec.ErrorObject{Code: -32000, Message: "execution reverted: AccessControl: account 0x70997970c51812dc3a010c7d01b50e0d17dc79c8 is missing role 0x9f2df0fed2c77648de5860a4cc508cd0818c85b8b8a1ab4ceeef8d981c8956a6"}, "execution reverted: AccessControl: account 0x70997970c51812dc3a010c7d01b50e0d17dc79c8 is missing role 0x9f2df0fed2c77648de5860a4cc508cd0818c85b8b8a1ab4ceeef8d981c8956a6"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := wrapError(test.err)
+			require.ErrorIs(t, err, ErrExecutionReverted)
+			require.Equal(t, test.want, err.Error())
+
+			var rev *RevertError
+			require.True(t, errors.As(err, &rev))
+			require.Same(t, rev, RevertFromError(err))
+
+			var obj *codec.ErrorObject
+			require.True(t, errors.As(err, &obj), "node error stays reachable")
+		})
+	}
+
+	rev := RevertFromError(wrapError(&codec.ErrorObject{Code: 3, Message: "execution reverted", Data: custom}))
+	require.Equal(t, "InsufficientBalance(address,uint256,uint256)", rev.Custom)
+	require.Equal(t, ethgo.HexToAddress("0x"+strings.Repeat("ab", 20)), rev.Args["account"])
+	require.Equal(t, big.NewInt(7), rev.Args["needed"])
+
+	require.Nil(t, RevertFromError(&codec.ErrorObject{Code: -32000, Message: "nonce too low"}))
+}
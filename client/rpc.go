@@ -0,0 +1,178 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+// DefaultTimeout bounds calls whose context carries no deadline.
+const DefaultTimeout = 30 * time.Second
+
+// HTTPError is returned when an HTTP endpoint answers with a non-2xx status
+// and a body that is not a JSON-RPC error.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // from the Retry-After header, if any
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("http %d: %s", e.StatusCode, e.Body)
+}
+
+// httpTransport is a context-aware JSON-RPC transport over net/http.
+type httpTransport struct {
+	url    string
+	client *http.Client
+	id     atomic.Uint64
+}
+
+func isHTTPEndpoint(endpoint string) bool {
+	return strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://")
+}
+
+func (t *httpTransport) post(ctx context.Context, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		// Some providers send JSON-RPC errors (reverts, rate limits) with a
+		// 4xx/5xx status; the error object carries the code and data.
+		var rpcRes codec.Response
+		if err := json.Unmarshal(data, &rpcRes); err == nil && rpcRes.Error != nil {
+			return nil, rpcRes.Error
+		}
+		herr := &HTTPError{StatusCode: res.StatusCode, Body: strings.TrimSpace(string(data))}
+		if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+			herr.RetryAfter = time.Duration(secs) * time.Second
+		}
+		return nil, herr
+	}
+	return data, nil
+}
+
+func (t *httpTransport) call(ctx context.Context, method string, out any, params ...any) error {
+	req := codec.Request{JsonRPC: "2.0", ID: t.id.Add(1), Method: method}
+	if len(params) > 0 {
+		p, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = p
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	data, err := t.post(ctx, body)
+	if err != nil {
+		return err
+	}
+
+	var res codec.Response
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(res.Result, out)
+}
+
+// RawCall performs a raw JSON-RPC call with the default timeout.
+func (c *Client) RawCall(method string, out any, params ...any) error {
+	return c.RawCallContext(context.Background(), method, out, params...)
+}
+
+// RawCallContext performs a raw JSON-RPC call. out must be a pointer the result
+// can be JSON-decoded into (or nil to discard it).
+//
+// When ctx has no deadline, Client.Timeout (DefaultTimeout if zero) applies.
+// HTTP requests are aborted on cancellation; for WS/IPC endpoints the call
+// returns ctx.Err() immediately and the late response is discarded.
+// Node errors are classified (see Classify) and retried per Client.Retry.
+func (c *Client) RawCallContext(ctx context.Context, method string, out any, params ...any) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if c.Retry == nil {
+		return c.attempt(ctx, method, out, params...)
+	}
+	return c.Retry.retry(ctx, method, func() error {
+		return c.attempt(ctx, method, out, params...)
+	})
+}
+
+func (c *Client) attempt(ctx context.Context, method string, out any, params ...any) error {
+	if c.pool != nil {
+		return c.pool.call(ctx, method, out, params...)
+	}
+	if c.http != nil {
+		return wrapError(c.http.call(ctx, method, out, params...))
+	}
+
+	// The ethgo transport cannot be interrupted; decode into a private value so
+	// a response arriving after cancellation never touches out.
+	var raw json.RawMessage
+	done := make(chan error, 1)
+	go func() { done <- c.rpc.Call(method, &raw, params...) }()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return wrapError(err)
+		}
+		if out == nil {
+			return nil
+		}
+		return json.Unmarshal(raw, out)
+	}
+}
+
+// criticalRead is RawCallContext, but multi-endpoint clients configured with a
+// quorum require that many endpoints to return the same result.
+func (c *Client) criticalRead(ctx context.Context, method string, out any, params ...any) error {
+	if c.pool == nil || c.pool.opts.Quorum <= 1 {
+		return c.RawCallContext(ctx, method, out, params...)
+	}
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	return c.pool.quorumCall(ctx, method, out, params...)
+}
+
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer answers JSON-RPC requests from results (method -> raw JSON result).
+// Results starting with {"code": are sent as JSON-RPC errors. Methods missing
+// from results block until the request is cancelled.
+func newTestServer(t *testing.T, results map[string]string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64 `json:"id"`
+			Method string `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		res, ok := results[req.Method]
+		if !ok {
+			<-r.Context().Done()
+			return
+		}
+		if strings.HasPrefix(res, `{"code":`) {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":%s}`, req.ID, res)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, res)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCallContextCancel(t *testing.T) {
+	srv := newTestServer(t, map[string]string{
+		"eth_chainId":             `"0x1"`,
+		"eth_getTransactionCount": `"0x7"`,
+	})
+	cli, err := NewClient(srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), cli.ChainId.Int64())
+
+	n, err := cli.NonceAtContext(context.Background(), "0x0000000000000000000000000000000000000001", nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), n)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = cli.BlockNumberContext(ctx)
+	require.True(t, errors.Is(err, context.DeadlineExceeded), err)
+
+	cli.Timeout = 50 * time.Millisecond
+	_, err = cli.BlockNumber()
+	require.True(t, errors.Is(err, context.DeadlineExceeded), err)
+}
+
+func TestHTTPErrorStatus(t *testing.T) {
+	revert := "0x4e487b71" + strings.Repeat("0", 62) + "11"
+	bodies := map[string]struct {
+		status int
+		body   string
+	}{
+		"eth_call":        {http.StatusBadRequest, `{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted","data":"` + revert + `"}}`},
+		"eth_blockNumber": {http.StatusInternalServerError, `{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"limit exceeded"}}`},
+		"eth_gasPrice":    {http.StatusBadGateway, "<html>bad gateway</html>"},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		b := bodies[req.Method]
+		w.WriteHeader(b.status)
+		fmt.Fprint(w, b.body)
+	}))
+	defer srv.Close()
+	cli, err := dial(srv.URL)
+	require.NoError(t, err)
+
+	// A JSON-RPC error body is classified as such, whatever the status.
+	err = cli.RawCall("eth_call", nil)
+	require.ErrorIs(t, err, ErrExecutionReverted)
+	var rev *RevertError
+	require.ErrorAs(t, err, &rev)
+	require.Equal(t, int64(0x11), rev.Panic.Int64())
+	require.ErrorIs(t, cli.RawCall("eth_blockNumber", nil), ErrRateLimited)
+
+	// Other bodies stay HTTP errors.
+	var httpErr *HTTPError
+	require.ErrorAs(t, cli.RawCall("eth_gasPrice", nil), &httpErr)
+	require.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+}
@@ -0,0 +1,259 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+)
+
+// TransferLogAddress is the pseudo-contract that emits ERC-20 style Transfer
+// logs for native ETH transfers when TraceTransfers is on.
+var TransferLogAddress = ethgo.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+// transferTopic is keccak256("Transfer(address,address,uint256)").
+var transferTopic = ethgo.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+
+// SimCall is one call of an eth_simulateV1 block. Nil fields are filled by
+// the node. No signature is needed.
+type SimCall struct {
+	From                 ethgo.Address
+	To                   *ethgo.Address // nil for contract creation
+	Nonce                *uint64
+	Gas                  *uint64
+	GasPrice             *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	MaxFeePerBlobGas     *big.Int
+	Value                *big.Int
+	Data                 []byte
+	AccessList           []AccessTuple
+	BlobVersionedHashes  []ethgo.Hash
+	AuthorizationList    []SimAuthorization
+}
+
+// SimAuthorization is an EIP-7702 authorization in a SimCall.
+type SimAuthorization struct {
+	ChainID *big.Int
+	Address ethgo.Address
+	Nonce   uint64
+	YParity uint64
+	R, S    *big.Int
+}
+
+func (s *SimCall) MarshalJSON() ([]byte, error) {
+	out := map[string]any{"from": s.From.String()}
+	if s.To != nil {
+		out["to"] = s.To.String()
+	}
+	if s.Nonce != nil {
+		out["nonce"] = fmt.Sprintf("0x%x", *s.Nonce)
+	}
+	if s.Gas != nil {
+		out["gas"] = fmt.Sprintf("0x%x", *s.Gas)
+	}
+	for key, v := range map[string]*big.Int{
+		"gasPrice":             s.GasPrice,
+		"maxFeePerGas":         s.MaxFeePerGas,
+		"maxPriorityFeePerGas": s.MaxPriorityFeePerGas,
+		"maxFeePerBlobGas":     s.MaxFeePerBlobGas,
+		"value":                s.Value,
+	} {
+		if v != nil {
+			out[key] = fmt.Sprintf("0x%x", v)
+		}
+	}
+	if len(s.Data) > 0 {
+		out["input"] = fmt.Sprintf("0x%x", s.Data)
+	}
+	if s.AccessList != nil {
+		out["accessList"] = s.AccessList
+	}
+	if s.BlobVersionedHashes != nil {
+		out["blobVersionedHashes"] = s.BlobVersionedHashes
+	}
+	if s.AuthorizationList != nil {
+		auths := make([]map[string]string, len(s.AuthorizationList))
+		for i, a := range s.AuthorizationList {
+			auths[i] = map[string]string{
+				"chainId": fmt.Sprintf("0x%x", orZero(a.ChainID)),
+				"address": a.Address.String(),
+				"nonce":   fmt.Sprintf("0x%x", a.Nonce),
+				"yParity": fmt.Sprintf("0x%x", a.YParity),
+				"r":       fmt.Sprintf("0x%x", orZero(a.R)),
+				"s":       fmt.Sprintf("0x%x", orZero(a.S)),
+			}
+		}
+		out["authorizationList"] = auths
+	}
+	return json.Marshal(out)
+}
+
+func orZero(x *big.Int) *big.Int {
+	if x == nil {
+		return new(big.Int)
+	}
+	return x
+}
+
+// SimBlock is one simulated block: optional overrides applied before its
+// calls, then the calls in order. Each block builds on the previous one.
+type SimBlock struct {
+	BlockOverrides *BlockOverride `json:"blockOverrides,omitempty"`
+	StateOverrides StateOverride  `json:"stateOverrides,omitempty"`
+	Calls          []*SimCall     `json:"calls"`
+}
+
+// SimulateOptions are the eth_simulateV1 flags.
+type SimulateOptions struct {
+	// Validation applies the checks of real transactions (nonce, balance,
+	// base fee); without it calls behave like eth_call.
+	Validation bool
+	// TraceTransfers adds a Transfer log from TransferLogAddress for every
+	// ETH transfer.
+	TraceTransfers bool
+	// ReturnFullTransactions fills the block's transactions with objects
+	// instead of hashes.
+	ReturnFullTransactions bool
+}
+
+// SimBlockResult is one simulated block with its call results.
+type SimBlockResult struct {
+	Number    uint64
+	Hash      ethgo.Hash
+	Timestamp uint64
+	GasLimit  uint64
+	GasUsed   uint64
+	BaseFee   *big.Int
+	Calls     []*SimCallResult
+}
+
+// SimCallResult is the outcome of one SimCall.
+type SimCallResult struct {
+	Success    bool
+	ReturnData []byte
+	GasUsed    uint64
+	Logs       []*ethgo.Log
+	// Err is set for failed calls; reverts are *RevertError.
+	Err error
+}
+
+// Transfer is a native ETH transfer reported with TraceTransfers.
+type Transfer struct {
+	From, To ethgo.Address
+	Value    *big.Int
+}
+
+// Transfers returns the ETH transfers among r's logs (TraceTransfers only).
+func (r *SimCallResult) Transfers() []Transfer {
+	var out []Transfer
+	for _, l := range r.Logs {
+		if l.Address != TransferLogAddress || len(l.Topics) != 3 || l.Topics[0] != transferTopic {
+			continue
+		}
+		out = append(out, Transfer{
+			From:  utils.TopicToAddress(l.Topics[1]),
+			To:    utils.TopicToAddress(l.Topics[2]),
+			Value: new(big.Int).SetBytes(l.Data),
+		})
+	}
+	return out
+}
+
+// SimulateV1 runs blocks of calls on top of block with eth_simulateV1. opts
+// may be nil. A call that fails does not fail the request; see
+// SimCallResult.Err. Validation failures (e.g. a bad nonce) fail the whole
+// request.
+func (c *Client) SimulateV1(blocks []*SimBlock, block ethgo.BlockNumber, opts *SimulateOptions) ([]*SimBlockResult, error) {
+	return c.SimulateV1Context(context.Background(), blocks, block, opts)
+}
+
+// SimulateV1Context is SimulateV1 with a context.
+func (c *Client) SimulateV1Context(ctx context.Context, blocks []*SimBlock, block ethgo.BlockNumber, opts *SimulateOptions) ([]*SimBlockResult, error) {
+	var o SimulateOptions
+	if opts != nil {
+		o = *opts
+	}
+	req := map[string]any{
+		"blockStateCalls":        blocks,
+		"validation":             o.Validation,
+		"traceTransfers":         o.TraceTransfers,
+		"returnFullTransactions": o.ReturnFullTransactions,
+	}
+	var raw []struct {
+		Number    string     `json:"number"`
+		Hash      ethgo.Hash `json:"hash"`
+		Timestamp string     `json:"timestamp"`
+		GasLimit  string     `json:"gasLimit"`
+		GasUsed   string     `json:"gasUsed"`
+		BaseFee   *string    `json:"baseFeePerGas"`
+		Calls     []struct {
+			Status     string       `json:"status"`
+			ReturnData string       `json:"returnData"`
+			GasUsed    string       `json:"gasUsed"`
+			Logs       []*ethgo.Log `json:"logs"`
+			Error      *simError    `json:"error"`
+		} `json:"calls"`
+	}
+	if err := c.RawCallContext(ctx, "eth_simulateV1", &raw, req, block.String()); err != nil {
+		return nil, err
+	}
+
+	out := make([]*SimBlockResult, len(raw))
+	for i, b := range raw {
+		res := &SimBlockResult{Hash: b.Hash}
+		var err error
+		for _, f := range []struct {
+			dst *uint64
+			s   string
+		}{{&res.Number, b.Number}, {&res.Timestamp, b.Timestamp}, {&res.GasLimit, b.GasLimit}, {&res.GasUsed, b.GasUsed}} {
+			if *f.dst, err = utils.StrToU64(f.s); err != nil {
+				return nil, err
+			}
+		}
+		if b.BaseFee != nil {
+			if res.BaseFee, err = utils.StrToBig(*b.BaseFee); err != nil {
+				return nil, err
+			}
+		}
+		for _, call := range b.Calls {
+			r := &SimCallResult{Success: call.Status == "0x1", Logs: call.Logs}
+			if r.ReturnData, err = utils.FromHex(call.ReturnData); err != nil {
+				return nil, err
+			}
+			if r.GasUsed, err = utils.StrToU64(call.GasUsed); err != nil {
+				return nil, err
+			}
+			if !r.Success {
+				r.Err = simCallError(call.Error, r.ReturnData)
+			}
+			res.Calls = append(res.Calls, r)
+		}
+		out[i] = res
+	}
+	return out, nil
+}
+
+// simError is the error object of a failed call.
+type simError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data"`
+}
+
+func simCallError(e *simError, returnData []byte) error {
+	if e == nil {
+		return DecodeRevert(returnData)
+	}
+	if e.Code == codeExecutionReverted {
+		data := returnData
+		if d, err := utils.FromHex(e.Data); err == nil && len(d) > 0 {
+			data = d
+		}
+		return DecodeRevert(data)
+	}
+	return wrapError(fmt.Errorf("%s (code %d)", e.Message, e.Code))
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+)
+
+func TestSimulateV1(t *testing.T) {
+	reason := "insufficient allowance"
+	revert := fmt.Sprintf("0x08c379a0%064x%064x%-64s", 32, len(reason), hex.EncodeToString([]byte(reason)))
+	revert = strings.ReplaceAll(revert, " ", "0")
+	word := func(n int) string { return fmt.Sprintf("0x%064x", n) }
+	log := fmt.Sprintf(`{"address":%q,"topics":[%q,%q,%q],"data":%q,"blockNumber":"0x65","transactionHash":%q,"transactionIndex":"0x0","blockHash":%q,"logIndex":"0x0","removed":false}`,
+		TransferLogAddress.String(), transferTopic.String(), word(1), word(2), word(1000), word(0), word(0))
+	result := fmt.Sprintf(`[{"number":"0x65","hash":%q,"timestamp":"0x6553f100","gasLimit":"0x1c9c380","gasUsed":"0xa410","baseFeePerGas":"0x7","calls":[`+
+		`{"status":"0x1","returnData":"0x","gasUsed":"0x5208","logs":[%s]},`+
+		`{"status":"0x0","returnData":%q,"gasUsed":"0x5208","logs":[],"error":{"code":3,"message":"execution reverted","data":%q}}]}]`,
+		word(1), log, revert, revert)
+
+	srv := newTestServer(t, map[string]string{"eth_simulateV1": result})
+	cli, err := dial(srv.URL)
+	require.NoError(t, err)
+
+	to := ethgo.HexToAddress("0x0000000000000000000000000000000000000002")
+	blocks, err := cli.SimulateV1([]*SimBlock{{
+		StateOverrides: NewStateOverride().Balance(ethgo.HexToAddress("0x0000000000000000000000000000000000000001"), ethgo.Ether(1)),
+		Calls:          []*SimCall{{To: &to, Value: ethgo.Ether(0)}, {To: &to, Data: []byte{1}}},
+	}}, ethgo.Latest, &SimulateOptions{TraceTransfers: true})
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	require.Equal(t, uint64(0x65), blocks[0].Number)
+	require.Equal(t, int64(7), blocks[0].BaseFee.Int64())
+
+	ok := blocks[0].Calls[0]
+	require.True(t, ok.Success)
+	require.NoError(t, ok.Err)
+	require.Equal(t, uint64(21000), ok.GasUsed)
+	transfers := ok.Transfers()
+	require.Len(t, transfers, 1)
+	require.Equal(t, to, transfers[0].To)
+	require.Equal(t, int64(1000), transfers[0].Value.Int64())
+
+	failed := blocks[0].Calls[1]
+	require.False(t, failed.Success)
+	require.ErrorIs(t, failed.Err, ErrExecutionReverted)
+	var rev *RevertError
+	require.ErrorAs(t, failed.Err, &rev)
+	require.Equal(t, reason, rev.Reason)
+}
@@ -0,0 +1,430 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+var ErrNoWSEndpoint = errors.New("subscriptions need a ws:// or wss:// endpoint (set Client.WSEndpoint)")
+
+const (
+	// MaxBackfillBlocks caps how many blocks are replayed after a reconnect.
+	MaxBackfillBlocks = 256
+
+	subscriptionBuffer = 128
+	minReconnectDelay  = 500 * time.Millisecond
+	maxReconnectDelay  = 30 * time.Second
+)
+
+// Keepalive for subscription connections: a ping is sent every
+// wsPingInterval, and a connection that delivers neither a message nor a
+// pong for wsIdleTimeout is treated as dropped (e.g. half-open after a NAT
+// or load balancer timeout) and reconnected.
+var (
+	wsPingInterval = 20 * time.Second
+	wsIdleTimeout  = 60 * time.Second
+)
+
+// Subscription delivers notifications from an eth_subscribe stream.
+//
+// The WebSocket connection is re-established and the subscription renewed
+// whenever it drops. Heads and logs missed while disconnected are fetched
+// through the Client (HTTP or WS) and delivered before new notifications;
+// already delivered items are not repeated. Pending transactions cannot be
+// replayed.
+type Subscription[T any] struct {
+	ch     chan T
+	errc   chan error
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// C returns the delivery channel. It is closed after Unsubscribe.
+func (s *Subscription[T]) C() <-chan T { return s.ch }
+
+// Err reports connection problems and reconnect failures as they happen
+// (dropped if not read). The subscription keeps retrying; it is closed after
+// Unsubscribe.
+func (s *Subscription[T]) Err() <-chan error { return s.errc }
+
+// Unsubscribe ends the subscription, waits for its goroutine to exit and
+// closes C and Err. It is safe to call more than once.
+func (s *Subscription[T]) Unsubscribe() {
+	s.cancel()
+	<-s.done
+}
+
+// stream describes one subscription kind.
+type stream[T any] struct {
+	params []any
+	// accept does dedupe and bookkeeping; false drops the item.
+	accept func(T) bool
+	// synced runs after every successful (re)subscribe.
+	synced func(ctx context.Context)
+	// backfill returns items missed while disconnected; nil for none.
+	backfill func(ctx context.Context) ([]T, error)
+}
+
+/* ---------- Public API ---------- */
+
+// SubscribeNewHeads streams new block headers. ctx bounds the initial
+// subscription only; call Unsubscribe to stop.
+func (c *Client) SubscribeNewHeads(ctx context.Context) (*Subscription[*Header], error) {
+	var last uint64
+	recent := map[uint64]ethgo.Hash{}
+
+	st := &stream[*Header]{params: []any{"newHeads"}}
+	st.accept = func(h *Header) bool {
+		if recent[h.Number] == h.Hash {
+			return false
+		}
+		recent[h.Number] = h.Hash
+		delete(recent, h.Number-MaxBackfillBlocks)
+		last = max(last, h.Number)
+		return true
+	}
+	st.synced = func(context.Context) {}
+	st.backfill = func(ctx context.Context) ([]*Header, error) {
+		if last == 0 {
+			return nil, nil
+		}
+		head, err := c.BlockNumberContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		from := max(last+1, head-min(head, MaxBackfillBlocks-1))
+		var out []*Header
+		for n := from; n <= head; n++ {
+			b, err := c.BlockByNumberContext(ctx, ethgo.BlockNumber(n), false)
+			if err != nil {
+				return out, err
+			}
+			if b != nil {
+				out = append(out, b)
+			}
+		}
+		return out, nil
+	}
+	return subscribe(ctx, c, st)
+}
+
+// SubscribeLogs streams logs matching q. From/To/BlockHash in q are ignored.
+// ctx bounds the initial subscription only; call Unsubscribe to stop.
+func (c *Client) SubscribeLogs(ctx context.Context, q *FilterQuery) (*Subscription[*Log], error) {
+	type logKey struct {
+		block ethgo.Hash
+		index uint64
+	}
+	var synced uint64 // logs up to this block are delivered or being streamed
+	seen := map[logKey]uint64{}
+
+	st := &stream[*Log]{params: []any{"logs", filterParams(q, nil, nil)}}
+	st.accept = func(l *Log) bool {
+		k := logKey{l.BlockHash, l.LogIndex}
+		if _, ok := seen[k]; ok && !l.Removed {
+			return false
+		}
+		seen[k] = l.BlockNumber
+		if l.BlockNumber > synced {
+			synced = l.BlockNumber
+			for k, n := range seen {
+				if n+MaxBackfillBlocks < synced {
+					delete(seen, k)
+				}
+			}
+		}
+		return true
+	}
+	st.synced = func(ctx context.Context) {
+		if synced == 0 {
+			synced, _ = c.BlockNumberContext(ctx)
+		}
+	}
+	st.backfill = func(ctx context.Context) ([]*Log, error) {
+		if synced == 0 {
+			return nil, nil
+		}
+		head, err := c.BlockNumberContext(ctx)
+		if err != nil || head < synced {
+			return nil, err
+		}
+		from := ethgo.BlockNumber(max(synced, head-min(head, MaxBackfillBlocks-1)))
+		to := ethgo.BlockNumber(head)
+		var out []*Log
+		err = c.RawCallContext(ctx, "eth_getLogs", &out, filterParams(q, &from, &to))
+		return out, err
+	}
+	return subscribe(ctx, c, st)
+}
+
+// SubscribePendingTransactions streams hashes of transactions entering the
+// node's mempool. ctx bounds the initial subscription only.
+func (c *Client) SubscribePendingTransactions(ctx context.Context) (*Subscription[ethgo.Hash], error) {
+	st := &stream[ethgo.Hash]{
+		params: []any{"newPendingTransactions"},
+		accept: func(ethgo.Hash) bool { return true },
+		synced: func(context.Context) {},
+	}
+	return subscribe(ctx, c, st)
+}
+
+// filterParams encodes q for eth_getLogs/eth_subscribe. (ethgo's encoder
+// drops the address list when it has more than one entry.)
+func filterParams(q *FilterQuery, from, to *ethgo.BlockNumber) map[string]any {
+	out := map[string]any{}
+	if q == nil {
+		return out
+	}
+	switch len(q.Address) {
+	case 0:
+	case 1:
+		out["address"] = q.Address[0]
+	default:
+		out["address"] = q.Address
+	}
+	if len(q.Topics) > 0 {
+		out["topics"] = q.Topics
+	}
+	if from != nil {
+		out["fromBlock"] = from.String()
+	}
+	if to != nil {
+		out["toBlock"] = to.String()
+	}
+	return out
+}
+
+/* ---------- Connection loop ---------- */
+
+func (c *Client) wsEndpoint() (string, error) {
+	if c.WSEndpoint != "" {
+		return c.WSEndpoint, nil
+	}
+	if strings.HasPrefix(c.endpoint, "ws://") || strings.HasPrefix(c.endpoint, "wss://") {
+		return c.endpoint, nil
+	}
+	return "", ErrNoWSEndpoint
+}
+
+func subscribe[T any](ctx context.Context, c *Client, st *stream[T]) (*Subscription[T], error) {
+	url, err := c.wsEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	conn, id, err := dialSubscription(ctx, url, st.params)
+	if err != nil {
+		return nil, err
+	}
+	st.synced(ctx)
+
+	runCtx, stop := context.WithCancel(context.Background())
+	s := &Subscription[T]{
+		ch:     make(chan T, subscriptionBuffer),
+		errc:   make(chan error, 1),
+		cancel: stop,
+		done:   make(chan struct{}),
+	}
+	go s.run(runCtx, c, url, st, conn, id)
+	return s, nil
+}
+
+func (s *Subscription[T]) run(ctx context.Context, c *Client, url string, st *stream[T], conn *websocket.Conn, id string) {
+	defer close(s.done)
+	defer close(s.ch)
+	defer close(s.errc)
+
+	for {
+		err := s.read(ctx, conn, id, st)
+		if ctx.Err() != nil {
+			unsubscribe(conn, id)
+			conn.Close()
+			return
+		}
+		conn.Close()
+		s.report(err)
+
+		delay := minReconnectDelay
+		for {
+			t := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+			dctx, cancel := c.withTimeout(ctx)
+			conn, id, err = dialSubscription(dctx, url, st.params)
+			if err == nil {
+				st.synced(dctx)
+			}
+			cancel()
+			if err == nil {
+				break
+			}
+			s.report(fmt.Errorf("resubscribe: %w", err))
+			delay = min(delay*2, maxReconnectDelay)
+		}
+
+		if st.backfill != nil {
+			bctx, cancel := c.withTimeout(ctx)
+			items, err := st.backfill(bctx)
+			cancel()
+			if err != nil {
+				s.report(fmt.Errorf("backfill: %w", err))
+			}
+			for _, it := range items {
+				if !s.deliver(ctx, st, it) {
+					unsubscribe(conn, id)
+					conn.Close()
+					return
+				}
+			}
+		}
+	}
+}
+
+// read delivers notifications until the connection fails, goes idle or ctx
+// is done.
+func (s *Subscription[T]) read(ctx context.Context, conn *websocket.Conn, id string, st *stream[T]) error {
+	ping, idle := wsPingInterval, wsIdleTimeout
+
+	// mu orders deadline extensions against the cancellation deadline, so a
+	// late pong cannot push the read past Unsubscribe.
+	var mu sync.Mutex
+	extend := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return conn.SetReadDeadline(time.Now().Add(idle))
+	}
+	stop := context.AfterFunc(ctx, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		conn.SetReadDeadline(time.Now())
+	})
+	defer stop()
+	conn.SetPongHandler(func(string) error { return extend() })
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		t := time.NewTicker(ping)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(ping))
+			}
+		}
+	}()
+
+	for {
+		if err := extend(); err != nil {
+			return err
+		}
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		var msg struct {
+			Method string `json:"method"`
+			Params struct {
+				Subscription string          `json:"subscription"`
+				Result       json.RawMessage `json:"result"`
+			} `json:"params"`
+		}
+		if json.Unmarshal(data, &msg) != nil || msg.Method != "eth_subscription" || msg.Params.Subscription != id {
+			continue
+		}
+		var v T
+		if err := json.Unmarshal(msg.Params.Result, &v); err != nil {
+			s.report(fmt.Errorf("decode notification: %w", err))
+			continue
+		}
+		if !s.deliver(ctx, st, v) {
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Subscription[T]) deliver(ctx context.Context, st *stream[T], v T) bool {
+	if !st.accept(v) {
+		return true
+	}
+	select {
+	case s.ch <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *Subscription[T]) report(err error) {
+	select {
+	case s.errc <- err:
+	default:
+	}
+}
+
+var wsRequestID atomic.Uint64
+
+// dialSubscription opens a connection and calls eth_subscribe on it.
+func dialSubscription(ctx context.Context, url string, params []any) (*websocket.Conn, string, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetReadDeadline(deadline)
+		conn.SetWriteDeadline(deadline)
+	}
+	reqID := wsRequestID.Add(1)
+	req := map[string]any{"jsonrpc": "2.0", "id": reqID, "method": "eth_subscribe", "params": params}
+	if err := conn.WriteJSON(req); err != nil {
+		conn.Close()
+		return nil, "", err
+	}
+	for {
+		var res struct {
+			ID     uint64             `json:"id"`
+			Result string             `json:"result"`
+			Error  *codec.ErrorObject `json:"error"`
+		}
+		if err := conn.ReadJSON(&res); err != nil {
+			conn.Close()
+			return nil, "", err
+		}
+		if res.ID != reqID {
+			continue
+		}
+		if res.Error != nil {
+			conn.Close()
+			return nil, "", wrapError(res.Error)
+		}
+		conn.SetReadDeadline(time.Time{})
+		conn.SetWriteDeadline(time.Time{})
+		return conn, res.Result, nil
+	}
+}
+
+// unsubscribe is a best-effort eth_unsubscribe before a clean shutdown.
+func unsubscribe(conn *websocket.Conn, id string) {
+	conn.SetWriteDeadline(time.Now().Add(time.Second))
+	conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": wsRequestID.Add(1), "method": "eth_unsubscribe", "params": []any{id}})
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+func testHeader(n uint64) string {
+	h := fmt.Sprintf("0x%064x", n)
+	return fmt.Sprintf(`{"hash":%q,"parentHash":%q,"sha3Uncles":%q,"transactionsRoot":%q,"stateRoot":%q,"receiptsRoot":%q,`+
+		`"miner":"0x0000000000000000000000000000000000000000","number":"0x%x","gasLimit":"0x1","gasUsed":"0x0",`+
+		`"timestamp":"0x0","difficulty":"0x0","extraData":"0x"}`, h, h, h, h, h, h, n)
+}
+
+// newHeadsServer serves HTTP reads for blocks 1-3 and a newHeads WebSocket
+// at /ws. The first connection sends heads 1 and 2, then drops, or with
+// stall set stays open without reading (so pings go unanswered). Later
+// connections send heads 3 and 4.
+func newHeadsServer(t *testing.T, stall bool) *httptest.Server {
+	var conns atomic.Int32
+	hang := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ws" {
+			var req codec.Request
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			res := map[string]string{"eth_chainId": `"0x1"`, "eth_blockNumber": `"0x3"`}[req.Method]
+			if req.Method == "eth_getBlockByNumber" {
+				var params []any
+				require.NoError(t, json.Unmarshal(req.Params, &params))
+				var n uint64
+				fmt.Sscanf(params[0].(string), "0x%x", &n)
+				res = testHeader(n)
+			}
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, res)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		var req codec.Request
+		require.NoError(t, conn.ReadJSON(&req))
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"0xabc"}`, req.ID)))
+
+		first := conns.Add(1) == 1
+		heads := []uint64{1, 2} // first connection drops after block 2
+		if !first {
+			heads = []uint64{3, 4} // block 3 was backfilled over HTTP
+		}
+		for _, n := range heads {
+			msg := fmt.Sprintf(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xabc","result":%s}}`, testHeader(n))
+			conn.WriteMessage(websocket.TextMessage, []byte(msg))
+		}
+		switch {
+		case first && stall:
+			<-hang // half-open: no reads, no pongs, no close
+		case !first:
+			conn.ReadMessage() // hold until the client goes away
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(hang) })
+	return srv
+}
+
+// collectHeads subscribes to newHeads and returns the first n head numbers.
+func collectHeads(t *testing.T, srv *httptest.Server, n int) []uint64 {
+	cli, err := NewClient(srv.URL)
+	require.NoError(t, err)
+	cli.WSEndpoint = "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+
+	sub, err := cli.SubscribeNewHeads(t.Context())
+	require.NoError(t, err)
+
+	var got []uint64
+	timeout := time.After(5 * time.Second)
+	for len(got) < n {
+		select {
+		case h := <-sub.C():
+			got = append(got, h.Number)
+		case <-sub.Err():
+		case <-timeout:
+			t.Fatalf("timed out, got %v", got)
+		}
+	}
+
+	sub.Unsubscribe()
+	_, open := <-sub.C()
+	require.False(t, open)
+	return got
+}
+
+func TestSubscribeNewHeadsReconnect(t *testing.T) {
+	require.Equal(t, []uint64{1, 2, 3, 4}, collectHeads(t, newHeadsServer(t, false), 4))
+}
+
+func TestSubscribeIdleReconnect(t *testing.T) {
+	ping, idle := wsPingInterval, wsIdleTimeout
+	wsPingInterval, wsIdleTimeout = 20*time.Millisecond, 100*time.Millisecond
+	t.Cleanup(func() { wsPingInterval, wsIdleTimeout = ping, idle })
+
+	// The first connection goes silent without closing; the missed pongs
+	// trigger a reconnect, and block 3 is backfilled.
+	require.Equal(t, []uint64{1, 2, 3, 4}, collectHeads(t, newHeadsServer(t, true), 4))
+}
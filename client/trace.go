@@ -0,0 +1,464 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+)
+
+// TraceOptions configures the debug_trace* methods. Nil means defaults.
+type TraceOptions struct {
+	// OnlyTopCall skips sub-calls (callTracer).
+	OnlyTopCall bool
+	// WithLog records logs on each frame (callTracer).
+	WithLog bool
+	// Timeout is the node-side trace timeout, e.g. "10s".
+	Timeout string
+	// StateOverrides and BlockOverrides apply to TraceCall only.
+	StateOverrides StateOverride
+	BlockOverrides *BlockOverride
+}
+
+/* ---------- Call tracer ---------- */
+
+// CallFrame is one call in a callTracer tree.
+type CallFrame struct {
+	Type         string // CALL, STATICCALL, DELEGATECALL, CREATE, ...
+	From         ethgo.Address
+	To           ethgo.Address
+	Value        *big.Int // nil when the frame carries none
+	Gas          uint64
+	GasUsed      uint64
+	Input        []byte
+	Output       []byte
+	Error        string
+	RevertReason string
+	Calls        []*CallFrame
+	Logs         []*CallLog
+}
+
+// CallLog is a log recorded by callTracer with WithLog.
+type CallLog struct {
+	Address ethgo.Address
+	Topics  []ethgo.Hash
+	Data    []byte
+}
+
+func (f *CallFrame) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type         string        `json:"type"`
+		From         ethgo.Address `json:"from"`
+		To           ethgo.Address `json:"to"`
+		Value        string        `json:"value"`
+		Gas          string        `json:"gas"`
+		GasUsed      string        `json:"gasUsed"`
+		Input        string        `json:"input"`
+		Output       string        `json:"output"`
+		Error        string        `json:"error"`
+		RevertReason string        `json:"revertReason"`
+		Calls        []*CallFrame  `json:"calls"`
+		Logs         []struct {
+			Address ethgo.Address `json:"address"`
+			Topics  []ethgo.Hash  `json:"topics"`
+			Data    string        `json:"data"`
+		} `json:"logs"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*f = CallFrame{
+		Type:         raw.Type,
+		From:         raw.From,
+		To:           raw.To,
+		Error:        raw.Error,
+		RevertReason: raw.RevertReason,
+		Calls:        raw.Calls,
+	}
+	var err error
+	if raw.Value != "" {
+		if f.Value, err = utils.StrToBig(raw.Value); err != nil {
+			return err
+		}
+	}
+	if f.Gas, err = optU64(raw.Gas); err != nil {
+		return err
+	}
+	if f.GasUsed, err = optU64(raw.GasUsed); err != nil {
+		return err
+	}
+	if f.Input, err = utils.FromHex(raw.Input); err != nil {
+		return err
+	}
+	if f.Output, err = utils.FromHex(raw.Output); err != nil {
+		return err
+	}
+	for _, l := range raw.Logs {
+		d, err := utils.FromHex(l.Data)
+		if err != nil {
+			return err
+		}
+		f.Logs = append(f.Logs, &CallLog{Address: l.Address, Topics: l.Topics, Data: d})
+	}
+	return nil
+}
+
+func optU64(s string) (uint64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return utils.StrToU64(s)
+}
+
+// Failed reports whether the frame errored or reverted.
+func (f *CallFrame) Failed() bool { return f.Error != "" }
+
+// Revert returns the frame's revert decoded, or nil if it did not revert.
+func (f *CallFrame) Revert() *RevertError {
+	if !f.Failed() || !strings.Contains(f.Error, "revert") {
+		return nil
+	}
+	e := DecodeRevert(f.Output)
+	if e.Reason == "" {
+		e.Reason = f.RevertReason
+	}
+	return e
+}
+
+// Walk calls fn for f and every sub-call, depth first. depth is 0 for f.
+func (f *CallFrame) Walk(fn func(frame *CallFrame, depth int)) {
+	var walk func(*CallFrame, int)
+	walk = func(fr *CallFrame, d int) {
+		fn(fr, d)
+		for _, c := range fr.Calls {
+			walk(c, d+1)
+		}
+	}
+	walk(f, 0)
+}
+
+// DeepestFailure returns the innermost failed frame on the failing path, or
+// nil if f succeeded. That is usually where a revert originated.
+func (f *CallFrame) DeepestFailure() *CallFrame {
+	if !f.Failed() {
+		return nil
+	}
+	for i := len(f.Calls) - 1; i >= 0; i-- {
+		if d := f.Calls[i].DeepestFailure(); d != nil {
+			return d
+		}
+	}
+	return f
+}
+
+// TraceCall runs msg at block with the callTracer. opts may be nil.
+func (c *Client) TraceCall(msg *CallMsg, block ethgo.BlockNumber, opts *TraceOptions) (*CallFrame, error) {
+	return c.TraceCallContext(context.Background(), msg, block, opts)
+}
+
+// TraceCallContext is TraceCall with a context.
+func (c *Client) TraceCallContext(ctx context.Context, msg *CallMsg, block ethgo.BlockNumber, opts *TraceOptions) (*CallFrame, error) {
+	var out *CallFrame
+	if err := c.RawCallContext(ctx, "debug_traceCall", &out, msg, block.String(), traceConfig("callTracer", opts, true)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TraceTransaction replays a mined transaction with the callTracer. opts may be nil.
+func (c *Client) TraceTransaction(hash ethgo.Hash, opts *TraceOptions) (*CallFrame, error) {
+	return c.TraceTransactionContext(context.Background(), hash, opts)
+}
+
+// TraceTransactionContext is TraceTransaction with a context.
+func (c *Client) TraceTransactionContext(ctx context.Context, hash ethgo.Hash, opts *TraceOptions) (*CallFrame, error) {
+	var out *CallFrame
+	if err := c.RawCallContext(ctx, "debug_traceTransaction", &out, hash, traceConfig("callTracer", opts, false)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+/* ---------- Prestate tracer (diff mode) ---------- */
+
+// AccountState is an account as reported by the prestateTracer. Fields the
+// tracer omitted are nil.
+type AccountState struct {
+	Balance *big.Int
+	Nonce   *uint64
+	Code    []byte
+	Storage map[ethgo.Hash]ethgo.Hash
+}
+
+func (a *AccountState) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Balance string                    `json:"balance"`
+		Nonce   json.RawMessage           `json:"nonce"`
+		Code    string                    `json:"code"`
+		Storage map[ethgo.Hash]ethgo.Hash `json:"storage"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*a = AccountState{Storage: raw.Storage}
+	var err error
+	if raw.Balance != "" {
+		if a.Balance, err = utils.StrToBig(raw.Balance); err != nil {
+			return err
+		}
+	}
+	if len(raw.Nonce) > 0 {
+		// geth reports a number, other clients a hex string.
+		s := strings.Trim(string(raw.Nonce), `"`)
+		var n uint64
+		if strings.HasPrefix(s, "0x") {
+			n, err = utils.StrToU64(s)
+		} else {
+			n, err = strconv.ParseUint(s, 10, 64)
+		}
+		if err != nil {
+			return err
+		}
+		a.Nonce = &n
+	}
+	if raw.Code != "" {
+		if a.Code, err = utils.FromHex(raw.Code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrestateDiff is the prestateTracer diff-mode result: Pre holds the touched
+// accounts before the tx, Post only the fields that changed.
+type PrestateDiff struct {
+	Pre  map[ethgo.Address]*AccountState `json:"pre"`
+	Post map[ethgo.Address]*AccountState `json:"post"`
+}
+
+// AccountDiff is the change to one account.
+type AccountDiff struct {
+	Address                     ethgo.Address
+	BalanceBefore, BalanceAfter *big.Int
+	NonceBefore, NonceAfter     uint64
+	CodeChanged                 bool
+	Created, Deleted            bool
+	Storage                     map[ethgo.Hash][2]ethgo.Hash // slot -> {before, after}
+}
+
+// Accounts returns the per-account changes, sorted by address.
+func (d *PrestateDiff) Accounts() []*AccountDiff {
+	addrs := map[ethgo.Address]bool{}
+	for a := range d.Pre {
+		addrs[a] = true
+	}
+	for a := range d.Post {
+		addrs[a] = true
+	}
+
+	var out []*AccountDiff
+	for addr := range addrs {
+		pre, post := d.Pre[addr], d.Post[addr]
+		diff := &AccountDiff{Address: addr, Created: pre == nil, Storage: map[ethgo.Hash][2]ethgo.Hash{}}
+		if post == nil {
+			// Diff mode drops unchanged accounts, so pre-only means deleted.
+			diff.Deleted = true
+			var zero uint64
+			post = &AccountState{Balance: new(big.Int), Nonce: &zero, Code: []byte{}, Storage: map[ethgo.Hash]ethgo.Hash{}}
+		}
+		if pre == nil {
+			pre = &AccountState{Balance: new(big.Int)}
+		}
+		diff.BalanceBefore, diff.BalanceAfter = pre.Balance, pre.Balance
+		if post.Balance != nil {
+			diff.BalanceAfter = post.Balance
+		}
+		if pre.Nonce != nil {
+			diff.NonceBefore, diff.NonceAfter = *pre.Nonce, *pre.Nonce
+		}
+		if post.Nonce != nil {
+			diff.NonceAfter = *post.Nonce
+		}
+		diff.CodeChanged = post.Code != nil && !bytes.Equal(pre.Code, post.Code)
+		for slot, v := range post.Storage {
+			diff.Storage[slot] = [2]ethgo.Hash{pre.Storage[slot], v}
+		}
+		for slot, v := range pre.Storage {
+			if _, ok := post.Storage[slot]; !ok {
+				diff.Storage[slot] = [2]ethgo.Hash{v, {}} // cleared
+			}
+		}
+		out = append(out, diff)
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i].Address[:], out[j].Address[:]) < 0 })
+	return out
+}
+
+// TraceCallPrestate runs msg at block with the prestateTracer in diff mode.
+func (c *Client) TraceCallPrestate(msg *CallMsg, block ethgo.BlockNumber, opts *TraceOptions) (*PrestateDiff, error) {
+	return c.TraceCallPrestateContext(context.Background(), msg, block, opts)
+}
+
+// TraceCallPrestateContext is TraceCallPrestate with a context.
+func (c *Client) TraceCallPrestateContext(ctx context.Context, msg *CallMsg, block ethgo.BlockNumber, opts *TraceOptions) (*PrestateDiff, error) {
+	var out *PrestateDiff
+	if err := c.RawCallContext(ctx, "debug_traceCall", &out, msg, block.String(), traceConfig("prestateTracer", opts, true)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TraceTransactionPrestate replays a mined transaction with the
+// prestateTracer in diff mode.
+func (c *Client) TraceTransactionPrestate(hash ethgo.Hash, opts *TraceOptions) (*PrestateDiff, error) {
+	return c.TraceTransactionPrestateContext(context.Background(), hash, opts)
+}
+
+// TraceTransactionPrestateContext is TraceTransactionPrestate with a context.
+func (c *Client) TraceTransactionPrestateContext(ctx context.Context, hash ethgo.Hash, opts *TraceOptions) (*PrestateDiff, error) {
+	var out *PrestateDiff
+	if err := c.RawCallContext(ctx, "debug_traceTransaction", &out, hash, traceConfig("prestateTracer", opts, false)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func traceConfig(tracer string, opts *TraceOptions, call bool) map[string]any {
+	var o TraceOptions
+	if opts != nil {
+		o = *opts
+	}
+	cfg := map[string]any{"tracer": tracer}
+	switch tracer {
+	case "callTracer":
+		cfg["tracerConfig"] = map[string]any{"onlyTopCall": o.OnlyTopCall, "withLog": o.WithLog}
+	case "prestateTracer":
+		cfg["tracerConfig"] = map[string]any{"diffMode": true}
+	}
+	if o.Timeout != "" {
+		cfg["timeout"] = o.Timeout
+	}
+	if call {
+		if o.StateOverrides != nil {
+			cfg["stateOverrides"] = o.StateOverrides
+		}
+		if o.BlockOverrides != nil {
+			cfg["blockOverrides"] = o.BlockOverrides
+		}
+	}
+	return cfg
+}
+
+/* ---------- Pretty printing ---------- */
+
+// knownSelectors labels common function selectors in Format output.
+var (
+	selectorsMu    sync.RWMutex
+	knownSelectors = map[[4]byte]string{}
+)
+
+func init() {
+	for _, sig := range []string{
+		"transfer(address,uint256)",
+		"transferFrom(address,address,uint256)",
+		"approve(address,uint256)",
+		"balanceOf(address)",
+		"allowance(address,address)",
+		"totalSupply()",
+		"decimals()",
+		"symbol()",
+		"name()",
+		"permit(address,address,uint256,uint256,uint8,bytes32,bytes32)",
+		"nonces(address)",
+		"DOMAIN_SEPARATOR()",
+		"deposit()",
+		"withdraw(uint256)",
+		"safeTransferFrom(address,address,uint256)",
+		"safeTransferFrom(address,address,uint256,bytes)",
+		"safeTransferFrom(address,address,uint256,uint256,bytes)",
+		"setApprovalForAll(address,bool)",
+		"ownerOf(uint256)",
+		"isValidSignature(bytes32,bytes)",
+		"multicall(bytes[])",
+		"multicall(uint256,bytes[])",
+		"aggregate3((address,bool,bytes)[])",
+		"execute(bytes,bytes[],uint256)",
+		"transferWithAuthorization(address,address,uint256,uint256,uint256,bytes32,uint8,bytes32,bytes32)",
+		"receiveWithAuthorization(address,address,uint256,uint256,uint256,bytes32,uint8,bytes32,bytes32)",
+		"permitTransferFrom(((address,uint256),uint256,uint256),(address,uint256),address,bytes)",
+		"swapExactTokensForTokens(uint256,uint256,address[],address,uint256)",
+		"swapExactETHForTokens(uint256,address[],address,uint256)",
+		"exactInputSingle((address,address,uint24,address,uint256,uint256,uint160))",
+		"exactInput((bytes,address,uint256,uint256))",
+		"swap(address,bool,int256,uint160,bytes)",
+		"uniswapV3SwapCallback(int256,int256,bytes)",
+	} {
+		RegisterSelector(sig)
+	}
+}
+
+// RegisterSelector adds a function signature, e.g. "mint(address,uint256)",
+// to the labels used by CallFrame.Format.
+func RegisterSelector(signature string) {
+	var sel [4]byte
+	copy(sel[:], utils.Keccak([]byte(signature)))
+	selectorsMu.Lock()
+	knownSelectors[sel] = signature
+	selectorsMu.Unlock()
+}
+
+// Format renders the call tree one frame per line, indented by depth:
+//
+//	CALL <from> -> <router> swapExactTokensForTokens(...) gas=120000/95000 ! execution reverted
+//	  STATICCALL <router> -> <token> balanceOf(address) gas=3000/2600
+//	  CALL <router> -> <token> transfer(address,uint256) gas=60000/24000 ! execution reverted: insufficient balance
+func (f *CallFrame) Format() string {
+	var sb strings.Builder
+	f.Walk(func(fr *CallFrame, depth int) {
+		sb.WriteString(strings.Repeat("  ", depth))
+		fmt.Fprintf(&sb, "%s %s -> %s %s", fr.Type, fr.From, fr.To, selectorLabel(fr.Input))
+		if fr.Value != nil && fr.Value.Sign() != 0 {
+			fmt.Fprintf(&sb, " value=%s", fr.Value)
+		}
+		if fr.Gas != 0 || fr.GasUsed != 0 {
+			fmt.Fprintf(&sb, " gas=%d/%d", fr.Gas, fr.GasUsed)
+		}
+		if fr.Failed() {
+			msg := fr.Error
+			if rev := fr.Revert(); rev != nil {
+				msg = rev.Error()
+			}
+			fmt.Fprintf(&sb, " ! %s", msg)
+		}
+		sb.WriteByte('\n')
+	})
+	return sb.String()
+}
+
+func (f *CallFrame) String() string { return f.Format() }
+
+func selectorLabel(input []byte) string {
+	if len(input) < 4 {
+		if len(input) == 0 {
+			return "()"
+		}
+		return fmt.Sprintf("0x%x", input)
+	}
+	var sel [4]byte
+	copy(sel[:], input)
+	selectorsMu.RLock()
+	sig, ok := knownSelectors[sel]
+	selectorsMu.RUnlock()
+	if ok {
+		return sig
+	}
+	return fmt.Sprintf("0x%x", sel)
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+)
+
+func TestTraceCall(t *testing.T) {
+	reason := "insufficient balance"
+	revert := fmt.Sprintf("0x08c379a0%064x%064x%s", 32, len(reason), hex.EncodeToString([]byte(reason))+strings.Repeat("0", 64-2*len(reason)))
+	frames := fmt.Sprintf(`{"type":"CALL","from":"0x0000000000000000000000000000000000000001","to":"0x0000000000000000000000000000000000000002",`+
+		`"value":"0x0","gas":"0x1d4c0","gasUsed":"0x17318","input":"0x38ed1739","output":%q,"error":"execution reverted","calls":[`+
+		`{"type":"STATICCALL","from":"0x0000000000000000000000000000000000000002","to":"0x0000000000000000000000000000000000000003","gas":"0xbb8","gasUsed":"0xa28","input":"0x70a08231","output":"0x"},`+
+		`{"type":"CALL","from":"0x0000000000000000000000000000000000000002","to":"0x0000000000000000000000000000000000000003","gas":"0xea60","gasUsed":"0x5dc0","input":"0xa9059cbb","output":%q,"error":"execution reverted","revertReason":%q}]}`,
+		revert, revert, reason)
+	prestate := `{"pre":{"0x0000000000000000000000000000000000000001":{"balance":"0x10","nonce":4},` +
+		`"0x0000000000000000000000000000000000000003":{"balance":"0x0","storage":{"0x0000000000000000000000000000000000000000000000000000000000000001":"0x0000000000000000000000000000000000000000000000000000000000000005"}}},` +
+		`"post":{"0x0000000000000000000000000000000000000001":{"balance":"0x8","nonce":5},` +
+		`"0x0000000000000000000000000000000000000003":{"storage":{"0x0000000000000000000000000000000000000000000000000000000000000001":"0x0000000000000000000000000000000000000000000000000000000000000007"}}}}`
+
+	srv := newTestServer(t, map[string]string{"debug_traceCall": frames, "debug_traceTransaction": prestate})
+	cli, err := dial(srv.URL)
+	require.NoError(t, err)
+
+	to := ethgo.HexToAddress("0x0000000000000000000000000000000000000002")
+	frame, err := cli.TraceCall(&CallMsg{To: &to}, ethgo.Latest, nil)
+	require.NoError(t, err)
+	require.True(t, frame.Failed())
+	require.Len(t, frame.Calls, 2)
+	require.Equal(t, uint64(3000), frame.Calls[0].Gas)
+
+	deepest := frame.DeepestFailure()
+	require.Equal(t, "CALL", deepest.Type)
+	require.Equal(t, reason, deepest.Revert().Reason)
+
+	out := frame.Format()
+	require.Contains(t, out, "swapExactTokensForTokens(uint256,uint256,address[],address,uint256)")
+	require.Contains(t, out, "\n  STATICCALL ")
+	require.Contains(t, out, "balanceOf(address)")
+	require.Contains(t, out, "transfer(address,uint256) gas=60000/24000 ! execution reverted: insufficient balance")
+
+	diff, err := cli.TraceTransactionPrestate(ethgo.Hash{1}, nil)
+	require.NoError(t, err)
+	accounts := diff.Accounts()
+	require.Len(t, accounts, 2)
+	sender, token := accounts[0], accounts[1]
+	require.Equal(t, int64(16), sender.BalanceBefore.Int64())
+	require.Equal(t, int64(8), sender.BalanceAfter.Int64())
+	require.Equal(t, uint64(4), sender.NonceBefore)
+	require.Equal(t, uint64(5), sender.NonceAfter)
+	require.Equal(t, [2]ethgo.Hash{{31: 5}, {31: 7}}, token.Storage[ethgo.Hash{31: 1}])
+	require.Equal(t, int64(0), token.BalanceAfter.Int64())
+}
+
+func TestPrestateDiffCreatedDeleted(t *testing.T) {
+	nonce := uint64(3)
+	created, deleted := ethgo.Address{1}, ethgo.Address{2}
+	diff := &PrestateDiff{
+		Pre:  map[ethgo.Address]*AccountState{deleted: {Balance: big.NewInt(9), Nonce: &nonce, Code: []byte{0x60}}},
+		Post: map[ethgo.Address]*AccountState{created: {Balance: big.NewInt(5), Nonce: new(uint64)}},
+	}
+	accounts := diff.Accounts()
+	require.Len(t, accounts, 2)
+	c, d := accounts[0], accounts[1]
+
+	require.True(t, c.Created)
+	require.Equal(t, int64(0), c.BalanceBefore.Int64())
+	require.Equal(t, int64(5), c.BalanceAfter.Int64())
+
+	require.True(t, d.Deleted)
+	require.Equal(t, uint64(3), d.NonceBefore)
+	require.Equal(t, uint64(0), d.NonceAfter)
+	require.Equal(t, int64(0), d.BalanceAfter.Int64())
+	require.True(t, d.CodeChanged)
+}
+
+func TestRegisterSelectorConcurrent(t *testing.T) {
+	frame := &CallFrame{Type: "CALL", Input: []byte{0xa9, 0x05, 0x9c, 0xbb}}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterSelector(fmt.Sprintf("concurrent%d(uint256)", i))
+		}()
+		go func() {
+			defer wg.Done()
+			_ = frame.Format()
+		}()
+	}
+	wg.Wait()
+	require.Contains(t, frame.Format(), "transfer(address,uint256)")
+}
@@ -0,0 +1,322 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+)
+
+// TxStatus is a stage in a transaction's life.
+type TxStatus int
+
+const (
+	TxPending   TxStatus = iota // known to the node, not mined
+	TxIncluded                  // mined in a canonical block
+	TxConfirmed                 // TrackOptions.Confirmations blocks deep
+	TxSafe                      // at or below the "safe" block
+	TxFinalized                 // at or below the "finalized" block
+	TxDropped                   // gone from the mempool without being mined
+	TxReplaced                  // its sender nonce was mined by another tx
+	TxReorged                   // its block left the canonical chain; tracking continues
+)
+
+func (s TxStatus) String() string {
+	switch s {
+	case TxPending:
+		return "pending"
+	case TxIncluded:
+		return "included"
+	case TxConfirmed:
+		return "confirmed"
+	case TxSafe:
+		return "safe"
+	case TxFinalized:
+		return "finalized"
+	case TxDropped:
+		return "dropped"
+	case TxReplaced:
+		return "replaced"
+	case TxReorged:
+		return "reorged"
+	}
+	return "unknown"
+}
+
+// TxEvent is one status change reported by TrackTransaction.
+type TxEvent struct {
+	Status        TxStatus
+	Hash          ethgo.Hash
+	Receipt       *ethgo.Receipt // set from TxIncluded until TxReorged
+	Confirmations uint64         // head - receipt block + 1, when included
+}
+
+// TrackOptions configures TrackTransaction. Zero fields take the defaults below.
+type TrackOptions struct {
+	// Until is the last status to wait for: TxIncluded, TxConfirmed
+	// (default), TxSafe or TxFinalized.
+	Until TxStatus
+	// Confirmations needed for TxConfirmed (default 1).
+	Confirmations uint64
+	// PollInterval between checks (default 2s).
+	PollInterval time.Duration
+	// DropTimeout is how long a tx may be unknown to the node before it is
+	// reported dropped (default 5m).
+	DropTimeout time.Duration
+
+	// From and Nonce identify the tx for replacement detection. They are
+	// read from the node when the tx is seen; set them if the node may
+	// never see it (e.g. it was sent elsewhere).
+	From  string
+	Nonce *uint64
+
+	// OnEvent, when set, is called for each event before it is sent on the channel.
+	OnEvent func(TxEvent)
+}
+
+// tracker holds the state of one TrackTransaction call.
+type tracker struct {
+	c    *Client
+	hash ethgo.Hash
+	opts TrackOptions
+	out  chan TxEvent
+
+	receipt   *ethgo.Receipt
+	emitted   map[TxStatus]bool
+	firstMiss time.Time // when the tx was last found missing from the node
+}
+
+// TrackTransaction follows hash until it reaches opts.Until, is dropped or
+// replaced, or ctx ends. Events arrive on the returned channel, which is
+// closed when tracking stops. A reorg emits TxReorged and tracking resumes
+// from pending, or from included when the tx is already mined again.
+func (c *Client) TrackTransaction(ctx context.Context, hash ethgo.Hash, opts *TrackOptions) <-chan TxEvent {
+	t := &tracker{c: c, hash: hash, out: make(chan TxEvent, 16), emitted: map[TxStatus]bool{}}
+	if opts != nil {
+		t.opts = *opts
+	}
+	if t.opts.Until < TxIncluded || t.opts.Until > TxFinalized {
+		t.opts.Until = TxConfirmed
+	}
+	if t.opts.Confirmations == 0 {
+		t.opts.Confirmations = 1
+	}
+	if t.opts.PollInterval <= 0 {
+		t.opts.PollInterval = 2 * time.Second
+	}
+	if t.opts.DropTimeout <= 0 {
+		t.opts.DropTimeout = 5 * time.Minute
+	}
+	go t.run(ctx)
+	return t.out
+}
+
+// WaitForReceipt polls until hash is mined and returns its receipt.
+func (c *Client) WaitForReceipt(ctx context.Context, hash ethgo.Hash, poll time.Duration) (*ethgo.Receipt, error) {
+	if poll <= 0 {
+		poll = 2 * time.Second
+	}
+	for {
+		r, err := c.TransactionReceiptContext(ctx, hash)
+		if err != nil && ctx.Err() != nil {
+			return nil, err
+		}
+		if r != nil {
+			return r, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(poll):
+		}
+	}
+}
+
+func (t *tracker) run(ctx context.Context) {
+	defer close(t.out)
+	for {
+		if done := t.poll(ctx); done {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(t.opts.PollInterval):
+		}
+	}
+}
+
+// poll runs one check and reports whether tracking is over. RPC errors are
+// treated as transient.
+func (t *tracker) poll(ctx context.Context) bool {
+	receipt, err := t.c.TransactionReceiptContext(ctx, t.hash)
+	if err != nil {
+		return ctx.Err() != nil
+	}
+	if receipt != nil {
+		canonical, err := t.canonical(ctx, receipt)
+		if err != nil {
+			return ctx.Err() != nil
+		}
+		if !canonical {
+			receipt = nil // stale receipt from a reorged block
+		}
+	}
+
+	if receipt == nil {
+		if t.receipt != nil {
+			t.receipt = nil
+			t.resetFrom(TxPending)
+			if !t.emit(ctx, TxEvent{Status: TxReorged, Hash: t.hash}) {
+				return true
+			}
+		}
+		return t.pending(ctx)
+	}
+
+	if t.receipt != nil && t.receipt.BlockHash != receipt.BlockHash {
+		t.resetFrom(TxPending)
+		if !t.emit(ctx, TxEvent{Status: TxReorged, Hash: t.hash}) {
+			return true
+		}
+	}
+	t.receipt = receipt
+	t.firstMiss = time.Time{}
+	return t.included(ctx)
+}
+
+// pending handles a tx without a canonical receipt.
+func (t *tracker) pending(ctx context.Context) bool {
+	var tx *struct {
+		From  ethgo.Address `json:"from"`
+		Nonce string        `json:"nonce"`
+	}
+	if err := t.c.RawCallContext(ctx, "eth_getTransactionByHash", &tx, t.hash); err != nil {
+		return ctx.Err() != nil
+	}
+	if tx != nil {
+		if t.opts.From == "" {
+			t.opts.From = tx.From.String()
+		}
+		if t.opts.Nonce == nil {
+			if n, err := utils.StrToU64(tx.Nonce); err == nil {
+				t.opts.Nonce = &n
+			}
+		}
+	}
+
+	// Same sender nonce mined while our receipt is missing: replaced.
+	if t.opts.From != "" && t.opts.Nonce != nil {
+		mined, err := t.c.NonceAtContext(ctx, t.opts.From, ethgo.Latest)
+		if err == nil && mined > *t.opts.Nonce {
+			// Re-check: the receipt may have appeared between the two reads.
+			if r, err := t.c.TransactionReceiptContext(ctx, t.hash); err == nil && r == nil {
+				t.emit(ctx, TxEvent{Status: TxReplaced, Hash: t.hash})
+				return true
+			}
+			return false
+		}
+	}
+
+	if tx == nil {
+		if t.firstMiss.IsZero() {
+			t.firstMiss = time.Now()
+		} else if time.Since(t.firstMiss) >= t.opts.DropTimeout {
+			t.emit(ctx, TxEvent{Status: TxDropped, Hash: t.hash})
+			return true
+		}
+		return false
+	}
+	t.firstMiss = time.Time{}
+	if !t.emitted[TxPending] {
+		return !t.emit(ctx, TxEvent{Status: TxPending, Hash: t.hash})
+	}
+	return false
+}
+
+// included advances through included/confirmed/safe/finalized.
+func (t *tracker) included(ctx context.Context) bool {
+	head, err := t.c.BlockNumberContext(ctx)
+	if err != nil {
+		return ctx.Err() != nil
+	}
+	ev := TxEvent{Hash: t.hash, Receipt: t.receipt}
+	if head >= t.receipt.BlockNumber {
+		ev.Confirmations = head - t.receipt.BlockNumber + 1
+	}
+
+	reached := []TxStatus{TxIncluded}
+	if ev.Confirmations >= t.opts.Confirmations {
+		reached = append(reached, TxConfirmed)
+		for _, tag := range []TxStatus{TxSafe, TxFinalized} {
+			if t.opts.Until < tag {
+				break
+			}
+			n, err := t.taggedBlock(ctx, tag.String())
+			if err != nil || n < t.receipt.BlockNumber {
+				break
+			}
+			reached = append(reached, tag)
+		}
+	}
+
+	for _, s := range reached {
+		if t.emitted[s] {
+			continue
+		}
+		ev.Status = s
+		if !t.emit(ctx, ev) {
+			return true
+		}
+		if s == t.opts.Until {
+			return true
+		}
+	}
+	return false
+}
+
+// canonical reports whether the receipt's block is still on the canonical chain.
+func (t *tracker) canonical(ctx context.Context, r *ethgo.Receipt) (bool, error) {
+	var b *struct {
+		Hash ethgo.Hash `json:"hash"`
+	}
+	if err := t.c.RawCallContext(ctx, "eth_getBlockByNumber", &b, ethgo.BlockNumber(r.BlockNumber).String(), false); err != nil {
+		return false, err
+	}
+	return b != nil && b.Hash == r.BlockHash, nil
+}
+
+// taggedBlock returns the number of the "safe" or "finalized" block.
+func (t *tracker) taggedBlock(ctx context.Context, tag string) (uint64, error) {
+	var b *struct {
+		Number string `json:"number"`
+	}
+	if err := t.c.RawCallContext(ctx, "eth_getBlockByNumber", &b, tag, false); err != nil {
+		return 0, err
+	}
+	if b == nil {
+		return 0, errors.New("no " + tag + " block")
+	}
+	return utils.StrToU64(b.Number)
+}
+
+func (t *tracker) resetFrom(s TxStatus) {
+	for st := s; st <= TxFinalized; st++ {
+		delete(t.emitted, st)
+	}
+}
+
+func (t *tracker) emit(ctx context.Context, ev TxEvent) bool {
+	t.emitted[ev.Status] = true
+	if t.opts.OnEvent != nil {
+		t.opts.OnEvent(ev)
+	}
+	select {
+	case t.out <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+func TestTrackTransaction(t *testing.T) {
+	blockHash := fmt.Sprintf("0x%064x", 5)
+	receipt := `{"from":"0x0000000000000000000000000000000000000001","transactionHash":"0x` + fmt.Sprintf("%064x", 1) +
+		`","blockHash":"` + blockHash + `","transactionIndex":"0x0","blockNumber":"0x5","gasUsed":"0x5208",` +
+		`"cumulativeGasUsed":"0x5208","logsBloom":"0x` + fmt.Sprintf("%0512x", 0) + `","status":"0x1","logs":[]}`
+
+	var polls, head atomic.Int32
+	head.Store(5)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req codec.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		res := "null"
+		switch req.Method {
+		case "eth_chainId":
+			res = `"0x1"`
+		case "eth_getTransactionReceipt":
+			if polls.Add(1) > 1 {
+				res = receipt
+			}
+		case "eth_getTransactionByHash":
+			res = `{"from":"0x0000000000000000000000000000000000000001","nonce":"0x7"}`
+		case "eth_getTransactionCount":
+			res = `"0x7"`
+		case "eth_getBlockByNumber":
+			res = `{"hash":"` + blockHash + `"}`
+		case "eth_blockNumber":
+			res = fmt.Sprintf(`"0x%x"`, head.Add(1)-1)
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, res)
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	var seen []TxStatus
+	events := cli.TrackTransaction(t.Context(), ethgo.Hash{1}, &TrackOptions{
+		Confirmations: 2,
+		PollInterval:  time.Millisecond,
+		OnEvent:       func(ev TxEvent) { seen = append(seen, ev.Status) },
+	})
+	var got []TxStatus
+	for ev := range events {
+		got = append(got, ev.Status)
+		if ev.Status == TxConfirmed {
+			require.Equal(t, uint64(2), ev.Confirmations)
+			require.Equal(t, uint64(5), ev.Receipt.BlockNumber)
+		}
+	}
+	require.Equal(t, []TxStatus{TxPending, TxIncluded, TxConfirmed}, got)
+	require.Equal(t, got, seen)
+}
+
+func TestTrackTransactionReplaced(t *testing.T) {
+	srv := newTestServer(t, map[string]string{
+		"eth_chainId":               `"0x1"`,
+		"eth_getTransactionReceipt": `null`,
+		"eth_getTransactionByHash":  `null`,
+		"eth_getTransactionCount":   `"0x8"`,
+	})
+	cli, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	nonce := uint64(7)
+	events := cli.TrackTransaction(t.Context(), ethgo.Hash{1}, &TrackOptions{
+		From:         "0x0000000000000000000000000000000000000001",
+		Nonce:        &nonce,
+		PollInterval: time.Millisecond,
+	})
+	ev := <-events
+	require.Equal(t, TxReplaced, ev.Status)
+	_, open := <-events
+	require.False(t, open)
+}
+
+func TestTrackTransactionReorg(t *testing.T) {
+	receipt := func(block string) string {
+		return `{"from":"0x0000000000000000000000000000000000000001","transactionHash":"0x` + fmt.Sprintf("%064x", 1) +
+			`","blockHash":"` + block + `","transactionIndex":"0x0","blockNumber":"0x5","gasUsed":"0x5208",` +
+			`"cumulativeGasUsed":"0x5208","logsBloom":"0x` + fmt.Sprintf("%0512x", 0) + `","status":"0x1","logs":[]}`
+	}
+	blockA, blockB := fmt.Sprintf("0x%064x", 0xa), fmt.Sprintf("0x%064x", 0xb)
+
+	// Receipt polls: pending, mined in A, A reorged out, mined in B.
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req codec.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		res := "null"
+		switch req.Method {
+		case "eth_chainId":
+			res = `"0x1"`
+		case "eth_getTransactionReceipt":
+			switch n := polls.Add(1); {
+			case n == 2:
+				res = receipt(blockA)
+			case n >= 4:
+				res = receipt(blockB)
+			}
+		case "eth_getTransactionByHash":
+			res = `{"from":"0x0000000000000000000000000000000000000001","nonce":"0x7"}`
+		case "eth_getTransactionCount":
+			res = `"0x7"`
+		case "eth_getBlockByNumber":
+			res = `{"hash":"` + blockA + `"}`
+			if polls.Load() >= 3 {
+				res = `{"hash":"` + blockB + `"}`
+			}
+		case "eth_blockNumber":
+			res = `"0x5"`
+			if polls.Load() >= 4 {
+				res = `"0x6"`
+			}
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, res)
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(srv.URL)
+	require.NoError(t, err)
+	var got []TxStatus
+	for ev := range cli.TrackTransaction(t.Context(), ethgo.Hash{1}, &TrackOptions{Confirmations: 2, PollInterval: time.Millisecond}) {
+		got = append(got, ev.Status)
+	}
+	require.Equal(t, []TxStatus{TxPending, TxIncluded, TxReorged, TxPending, TxIncluded, TxConfirmed}, got)
+}
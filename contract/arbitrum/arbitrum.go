@@ -0,0 +1,210 @@
+// Package arbitrum reads Arbitrum gas pricing from the NodeInterface virtual
+// contract and the ArbGasInfo precompile.
+package arbitrum
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+var (
+	// NodeInterfaceAddress is the NodeInterface virtual contract. It only
+	// exists for eth_call/eth_estimateGas on Arbitrum nodes.
+	NodeInterfaceAddress = ethgo.HexToAddress("0x00000000000000000000000000000000000000C8")
+	// ArbGasInfoAddress is the ArbGasInfo precompile.
+	ArbGasInfoAddress = ethgo.HexToAddress("0x000000000000000000000000000000000000006C")
+)
+
+const NodeInterfaceABI = `[
+  {"name":"gasEstimateComponents","type":"function","stateMutability":"payable","inputs":[{"name":"to","type":"address"},{"name":"contractCreation","type":"bool"},{"name":"data","type":"bytes"}],"outputs":[{"name":"gasEstimate","type":"uint64"},{"name":"gasEstimateForL1","type":"uint64"},{"name":"baseFee","type":"uint256"},{"name":"l1BaseFeeEstimate","type":"uint256"}]},
+  {"name":"gasEstimateL1Component","type":"function","stateMutability":"payable","inputs":[{"name":"to","type":"address"},{"name":"contractCreation","type":"bool"},{"name":"data","type":"bytes"}],"outputs":[{"name":"gasEstimateForL1","type":"uint64"},{"name":"baseFee","type":"uint256"},{"name":"l1BaseFeeEstimate","type":"uint256"}]}
+]`
+
+const ArbGasInfoABI = `[
+  {"name":"getPricesInWei","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"perL2Tx","type":"uint256"},{"name":"perL1CalldataByte","type":"uint256"},{"name":"perStorageAllocation","type":"uint256"},{"name":"perArbGasBase","type":"uint256"},{"name":"perArbGasCongestion","type":"uint256"},{"name":"perArbGasTotal","type":"uint256"}]},
+  {"name":"getL1BaseFeeEstimate","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+  {"name":"getMinimumGasPrice","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+type Runtime struct {
+	ni *abi.ABI
+	gi *abi.ABI
+}
+
+func New() (*Runtime, error) {
+	ni, err := abi.NewABI(NodeInterfaceABI)
+	if err != nil {
+		return nil, err
+	}
+	gi, err := abi.NewABI(ArbGasInfoABI)
+	if err != nil {
+		return nil, err
+	}
+	return &Runtime{ni: ni, gi: gi}, nil
+}
+
+func (r *Runtime) method(name string) (*abi.Method, error) {
+	m := r.ni.Methods[name]
+	if m == nil {
+		m = r.gi.Methods[name]
+	}
+	if m == nil {
+		return nil, errors.New("method not found: " + name)
+	}
+	return m, nil
+}
+
+/* ----------------------------- Pack (call data) ---------------------------- */
+
+// PackGasEstimateComponents builds calldata for
+// NodeInterface.gasEstimateComponents(to, contractCreation, data).
+func (r *Runtime) PackGasEstimateComponents(to *ethgo.Address, data []byte) ([]byte, error) {
+	return r.packEstimate("gasEstimateComponents", to, data)
+}
+
+// PackGasEstimateL1Component builds calldata for
+// NodeInterface.gasEstimateL1Component(to, contractCreation, data).
+func (r *Runtime) PackGasEstimateL1Component(to *ethgo.Address, data []byte) ([]byte, error) {
+	return r.packEstimate("gasEstimateL1Component", to, data)
+}
+
+// packEstimate encodes a NodeInterface estimate; a nil to means contract creation.
+func (r *Runtime) packEstimate(method string, to *ethgo.Address, data []byte) ([]byte, error) {
+	m, err := r.method(method)
+	if err != nil {
+		return nil, err
+	}
+	target := ethgo.ZeroAddress
+	if to != nil {
+		target = *to
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return m.Encode([]any{target, to == nil, data})
+}
+
+// PackNoArgs returns the selector of a parameterless ArbGasInfo getter.
+func (r *Runtime) PackNoArgs(method string) ([]byte, error) {
+	m, err := r.method(method)
+	if err != nil {
+		return nil, err
+	}
+	return m.ID(), nil
+}
+
+/* --------------------------- Decode (return data) -------------------------- */
+
+// Components is the NodeInterface gas estimate split.
+type Components struct {
+	GasEstimate       uint64   // total gas, L1 component included (0 for gasEstimateL1Component)
+	GasEstimateForL1  uint64   // L2 gas units that pay for the L1 calldata
+	BaseFee           *big.Int // L2 base fee
+	L1BaseFeeEstimate *big.Int // L1 base fee as seen by the sequencer
+}
+
+// DecodeGasEstimateComponents decodes gasEstimateComponents return data.
+func (r *Runtime) DecodeGasEstimateComponents(outputHex string) (*Components, error) {
+	out, err := r.decode("gasEstimateComponents", outputHex)
+	if err != nil {
+		return nil, err
+	}
+	c := &Components{}
+	if c.GasEstimate, err = field[uint64](out, "gasEstimate"); err != nil {
+		return nil, err
+	}
+	return c, decodeL1Fields(out, c)
+}
+
+// DecodeGasEstimateL1Component decodes gasEstimateL1Component return data.
+func (r *Runtime) DecodeGasEstimateL1Component(outputHex string) (*Components, error) {
+	out, err := r.decode("gasEstimateL1Component", outputHex)
+	if err != nil {
+		return nil, err
+	}
+	c := &Components{}
+	return c, decodeL1Fields(out, c)
+}
+
+func decodeL1Fields(out map[string]any, c *Components) error {
+	var err error
+	if c.GasEstimateForL1, err = field[uint64](out, "gasEstimateForL1"); err != nil {
+		return err
+	}
+	if c.BaseFee, err = field[*big.Int](out, "baseFee"); err != nil {
+		return err
+	}
+	c.L1BaseFeeEstimate, err = field[*big.Int](out, "l1BaseFeeEstimate")
+	return err
+}
+
+// Prices is ArbGasInfo.getPricesInWei.
+type Prices struct {
+	PerL2Tx              *big.Int
+	PerL1CalldataByte    *big.Int
+	PerStorageAllocation *big.Int
+	PerArbGasBase        *big.Int
+	PerArbGasCongestion  *big.Int
+	PerArbGasTotal       *big.Int
+}
+
+// DecodePricesInWei decodes getPricesInWei return data.
+func (r *Runtime) DecodePricesInWei(outputHex string) (*Prices, error) {
+	out, err := r.decode("getPricesInWei", outputHex)
+	if err != nil {
+		return nil, err
+	}
+	p := &Prices{}
+	for name, dst := range map[string]**big.Int{
+		"perL2Tx":              &p.PerL2Tx,
+		"perL1CalldataByte":    &p.PerL1CalldataByte,
+		"perStorageAllocation": &p.PerStorageAllocation,
+		"perArbGasBase":        &p.PerArbGasBase,
+		"perArbGasCongestion":  &p.PerArbGasCongestion,
+		"perArbGasTotal":       &p.PerArbGasTotal,
+	} {
+		if *dst, err = field[*big.Int](out, name); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// DecodeUint256 decodes a single-uint256 getter (getL1BaseFeeEstimate, getMinimumGasPrice).
+func (r *Runtime) DecodeUint256(outputHex string, method string) (*big.Int, error) {
+	out, err := r.decode(method, outputHex)
+	if err != nil {
+		return nil, err
+	}
+	return field[*big.Int](out, "0")
+}
+
+func (r *Runtime) decode(method, outputHex string) (map[string]any, error) {
+	b, err := utils.HexToBytes(outputHex)
+	if err != nil {
+		return nil, err
+	}
+	m, err := r.method(method)
+	if err != nil {
+		return nil, err
+	}
+	out, err := m.Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func field[T any](out map[string]any, name string) (T, error) {
+	v, ok := out[name].(T)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("unexpected %s type %T", name, out[name])
+	}
+	return v, nil
+}
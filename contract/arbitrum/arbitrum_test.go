@@ -0,0 +1,65 @@
+package arbitrum
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+func TestEstimate(t *testing.T) {
+	r, err := New()
+	require.NoError(t, err)
+
+	components, err := abi.Encode([]any{uint64(600_000), uint64(400_000), big.NewInt(1e7), big.NewInt(3e10)}, r.ni.Methods["gasEstimateComponents"].Outputs)
+	require.NoError(t, err)
+	minPrice, err := abi.Encode([]any{big.NewInt(1e7)}, r.gi.Methods["getMinimumGasPrice"].Outputs)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var q codec.Request
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&q))
+		result := `"0xa4b1"`
+		if q.Method == "eth_call" {
+			var params []json.RawMessage
+			require.NoError(t, json.Unmarshal(q.Params, &params))
+			var msg struct {
+				To ethgo.Address `json:"to"`
+			}
+			require.NoError(t, json.Unmarshal(params[0], &msg))
+			switch msg.To {
+			case NodeInterfaceAddress:
+				result = `"0x` + hex.EncodeToString(components) + `"`
+			case ArbGasInfoAddress:
+				result = `"0x` + hex.EncodeToString(minPrice) + `"`
+			}
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":%s}`, q.ID, result)
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient(srv.URL)
+	require.NoError(t, err)
+
+	to := ethgo.HexToAddress("0x0000000000000000000000000000000000000001")
+	e, err := r.Estimate(t.Context(), c, &client.CallMsg{To: &to, Data: []byte{1}}, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(400_000), e.GasEstimateForL1)
+	require.Equal(t, uint64(200_000), e.L2Gas)
+	require.Equal(t, int64(4e12), e.L1Cost.Int64())
+	require.Equal(t, uint64(220_000+520_000), e.GasLimit)
+	require.Equal(t, int64(2e7), e.FeeCap.Int64())
+
+	e, err = r.Estimate(t.Context(), c, &client.CallMsg{To: &to}, &EstimateOptions{L1Margin: -1, L2Margin: -1})
+	require.NoError(t, err)
+	require.Equal(t, uint64(600_000), e.GasLimit)
+}
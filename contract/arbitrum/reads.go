@@ -0,0 +1,154 @@
+package arbitrum
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/umbracle/ethgo"
+)
+
+/* ---------------------------- Reads (eth_call) ----------------------------- */
+
+// GasEstimateComponents estimates msg through NodeInterface and returns the
+// total gas with its L1 part. msg is the transaction to estimate; its From,
+// Value and Gas are kept on the call.
+func (r *Runtime) GasEstimateComponents(ctx context.Context, c *client.Client, msg *client.CallMsg) (*Components, error) {
+	out, err := r.callNodeInterface(ctx, c, msg, r.PackGasEstimateComponents)
+	if err != nil {
+		return nil, err
+	}
+	return r.DecodeGasEstimateComponents(out)
+}
+
+// GasEstimateL1Component returns only the L1 part of msg's gas, which is
+// cheaper for the node than a full estimate.
+func (r *Runtime) GasEstimateL1Component(ctx context.Context, c *client.Client, msg *client.CallMsg) (*Components, error) {
+	out, err := r.callNodeInterface(ctx, c, msg, r.PackGasEstimateL1Component)
+	if err != nil {
+		return nil, err
+	}
+	return r.DecodeGasEstimateL1Component(out)
+}
+
+func (r *Runtime) callNodeInterface(ctx context.Context, c *client.Client, msg *client.CallMsg, pack func(*ethgo.Address, []byte) ([]byte, error)) (string, error) {
+	data, err := pack(msg.To, msg.Data)
+	if err != nil {
+		return "", err
+	}
+	call := &client.CallMsg{From: msg.From, To: &NodeInterfaceAddress, Data: data, Value: msg.Value, Gas: msg.Gas}
+	return c.CallContext(ctx, call, ethgo.Latest)
+}
+
+// PricesInWei reads ArbGasInfo.getPricesInWei.
+func (r *Runtime) PricesInWei(ctx context.Context, c *client.Client) (*Prices, error) {
+	out, err := r.callGasInfo(ctx, c, "getPricesInWei")
+	if err != nil {
+		return nil, err
+	}
+	return r.DecodePricesInWei(out)
+}
+
+// L1BaseFeeEstimate reads ArbGasInfo.getL1BaseFeeEstimate.
+func (r *Runtime) L1BaseFeeEstimate(ctx context.Context, c *client.Client) (*big.Int, error) {
+	out, err := r.callGasInfo(ctx, c, "getL1BaseFeeEstimate")
+	if err != nil {
+		return nil, err
+	}
+	return r.DecodeUint256(out, "getL1BaseFeeEstimate")
+}
+
+// MinimumGasPrice reads ArbGasInfo.getMinimumGasPrice (the L2 base fee floor).
+func (r *Runtime) MinimumGasPrice(ctx context.Context, c *client.Client) (*big.Int, error) {
+	out, err := r.callGasInfo(ctx, c, "getMinimumGasPrice")
+	if err != nil {
+		return nil, err
+	}
+	return r.DecodeUint256(out, "getMinimumGasPrice")
+}
+
+func (r *Runtime) callGasInfo(ctx context.Context, c *client.Client, method string) (string, error) {
+	data, err := r.PackNoArgs(method)
+	if err != nil {
+		return "", err
+	}
+	return c.CallContext(ctx, &client.CallMsg{To: &ArbGasInfoAddress, Data: data}, ethgo.Latest)
+}
+
+/* ------------------------------ Recommendation ----------------------------- */
+
+// EstimateOptions sets the safety margins of Estimate. Zero fields take the
+// defaults below; a negative margin disables it.
+type EstimateOptions struct {
+	// L1Margin pads the L1 gas (default 0.3). The L1 part is L1 cost divided
+	// by the L2 base fee, so it grows when L1 fees spike or L2 fees fall
+	// between estimation and inclusion.
+	L1Margin float64
+	// L2Margin pads the L2 execution gas (default 0.1).
+	L2Margin float64
+	// FeeCapMultiplier scales the L2 base fee into the fee cap (default 2).
+	FeeCapMultiplier float64
+}
+
+// GasEstimate is the L1/L2 breakdown of a transaction's gas with a
+// recommended gas limit and fee cap.
+type GasEstimate struct {
+	Components
+	L2Gas       uint64   // execution gas (GasEstimate - GasEstimateForL1)
+	L1Cost      *big.Int // wei paid for L1 data at the current base fee
+	MinGasPrice *big.Int
+
+	GasLimit uint64   // recommended gas limit
+	FeeCap   *big.Int // recommended maxFeePerGas (tips are ignored on Arbitrum)
+}
+
+// Estimate splits msg's gas into L1 and L2 parts and recommends a gas limit
+// and fee cap. opts may be nil.
+func (r *Runtime) Estimate(ctx context.Context, c *client.Client, msg *client.CallMsg, opts *EstimateOptions) (*GasEstimate, error) {
+	var o EstimateOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.L1Margin == 0 {
+		o.L1Margin = 0.3
+	}
+	if o.L2Margin == 0 {
+		o.L2Margin = 0.1
+	}
+	if o.FeeCapMultiplier <= 0 {
+		o.FeeCapMultiplier = 2
+	}
+
+	comp, err := r.GasEstimateComponents(ctx, c, msg)
+	if err != nil {
+		return nil, err
+	}
+	minPrice, err := r.MinimumGasPrice(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return recommend(comp, minPrice, o), nil
+}
+
+func recommend(comp *Components, minPrice *big.Int, o EstimateOptions) *GasEstimate {
+	e := &GasEstimate{Components: *comp, MinGasPrice: minPrice}
+	if comp.GasEstimate > comp.GasEstimateForL1 {
+		e.L2Gas = comp.GasEstimate - comp.GasEstimateForL1
+	}
+	e.L1Cost = new(big.Int).Mul(new(big.Int).SetUint64(comp.GasEstimateForL1), comp.BaseFee)
+	e.GasLimit = pad(e.L2Gas, o.L2Margin) + pad(comp.GasEstimateForL1, o.L1Margin)
+
+	f, _ := new(big.Float).Mul(new(big.Float).SetInt(comp.BaseFee), big.NewFloat(o.FeeCapMultiplier)).Int(nil)
+	if minPrice != nil && f.Cmp(minPrice) < 0 {
+		f.Set(minPrice)
+	}
+	e.FeeCap = f
+	return e
+}
+
+func pad(gas uint64, margin float64) uint64 {
+	if margin <= 0 {
+		return gas
+	}
+	return gas + uint64(float64(gas)*margin)
+}
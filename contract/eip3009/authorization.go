@@ -0,0 +1,259 @@
+package eip3009
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/gosunuts/ethtxbuilder/transaction"
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+)
+
+const (
+	transferWithAuthVRSSig = "transferWithAuthorization(address,address,uint256,uint256,uint256,bytes32,uint8,bytes32,bytes32)"
+	receiveWithAuthSig     = "receiveWithAuthorization(address,address,uint256,uint256,uint256,bytes32,bytes)"
+	receiveWithAuthVRSSig  = "receiveWithAuthorization(address,address,uint256,uint256,uint256,bytes32,uint8,bytes32,bytes32)"
+	cancelAuthSig          = "cancelAuthorization(address,bytes32,bytes)"
+	cancelAuthVRSSig       = "cancelAuthorization(address,bytes32,uint8,bytes32,bytes32)"
+)
+
+// Authorization is the payload of transferWithAuthorization and receiveWithAuthorization.
+type Authorization struct {
+	From        ethgo.Address
+	To          ethgo.Address
+	Value       *big.Int
+	ValidAfter  *big.Int
+	ValidBefore *big.Int
+	Nonce       ethgo.Hash
+}
+
+// NewNonce returns a random 32-byte authorization nonce.
+func NewNonce() (ethgo.Hash, error) {
+	var n ethgo.Hash
+	if _, err := rand.Read(n[:]); err != nil {
+		return ethgo.Hash{}, err
+	}
+	return n, nil
+}
+
+// ------------------------- Typed data ----------------------------------------
+
+var authorizationFields = []transaction.Field{
+	{Name: "from", Type: "address"},
+	{Name: "to", Type: "address"},
+	{Name: "value", Type: "uint256"},
+	{Name: "validAfter", Type: "uint256"},
+	{Name: "validBefore", Type: "uint256"},
+	{Name: "nonce", Type: "bytes32"},
+}
+
+func (a *Authorization) typedData(primary string, domain transaction.Domain) *transaction.TypedData {
+	return &transaction.TypedData{
+		Types:       transaction.Types{primary: authorizationFields},
+		PrimaryType: primary,
+		Domain:      domain,
+		Message: map[string]interface{}{
+			"from":        a.From.String(),
+			"to":          a.To.String(),
+			"value":       a.Value,
+			"validAfter":  a.ValidAfter,
+			"validBefore": a.ValidBefore,
+			"nonce":       a.Nonce.Bytes(),
+		},
+	}
+}
+
+// TransferTypedData builds the TransferWithAuthorization typed data.
+func (a *Authorization) TransferTypedData(domain transaction.Domain) *transaction.TypedData {
+	return a.typedData("TransferWithAuthorization", domain)
+}
+
+// ReceiveTypedData builds the ReceiveWithAuthorization typed data.
+func (a *Authorization) ReceiveTypedData(domain transaction.Domain) *transaction.TypedData {
+	return a.typedData("ReceiveWithAuthorization", domain)
+}
+
+// CancelTypedData builds the CancelAuthorization typed data.
+func CancelTypedData(domain transaction.Domain, authorizer ethgo.Address, nonce ethgo.Hash) *transaction.TypedData {
+	return &transaction.TypedData{
+		Types: transaction.Types{
+			"CancelAuthorization": {
+				{Name: "authorizer", Type: "address"},
+				{Name: "nonce", Type: "bytes32"},
+			},
+		},
+		PrimaryType: "CancelAuthorization",
+		Domain:      domain,
+		Message: map[string]interface{}{
+			"authorizer": authorizer.String(),
+			"nonce":      nonce.Bytes(),
+		},
+	}
+}
+
+// ------------------------- Sign ----------------------------------------------
+
+// SignTransfer signs a TransferWithAuthorization; the result is r || s || v (27/28).
+func (a *Authorization) SignTransfer(domain transaction.Domain, sign utils.SignFunc) ([]byte, error) {
+	return a.TransferTypedData(domain).Sign(sign)
+}
+
+// SignReceive signs a ReceiveWithAuthorization; the result is r || s || v (27/28).
+func (a *Authorization) SignReceive(domain transaction.Domain, sign utils.SignFunc) ([]byte, error) {
+	return a.ReceiveTypedData(domain).Sign(sign)
+}
+
+// SignCancel signs a CancelAuthorization; the result is r || s || v (27/28).
+func SignCancel(domain transaction.Domain, authorizer ethgo.Address, nonce ethgo.Hash, sign utils.SignFunc) ([]byte, error) {
+	return CancelTypedData(domain, authorizer, nonce).Sign(sign)
+}
+
+// ------------------------- Pack (tx data) ------------------------------------
+
+// PackTransferWithAuthVRS builds calldata for the (v, r, s) overload of
+// transferWithAuthorization used by USDC.
+func (r *Runtime) PackTransferWithAuthVRS(a *Authorization, signature []byte) ([]byte, error) {
+	return r.packAuthVRS(transferWithAuthVRSSig, a, signature)
+}
+
+// PackReceiveWithAuth builds calldata for receiveWithAuthorization(..., bytes signature).
+func (r *Runtime) PackReceiveWithAuth(a *Authorization, signature []byte) ([]byte, error) {
+	m := r.a.MethodsBySignature[receiveWithAuthSig]
+	if m == nil {
+		return nil, errors.New("method not found: " + receiveWithAuthSig)
+	}
+	return m.Encode([]interface{}{
+		a.From, a.To, a.Value, a.ValidAfter, a.ValidBefore, a.Nonce, signature,
+	})
+}
+
+// PackReceiveWithAuthVRS builds calldata for receiveWithAuthorization(..., v, r, s).
+func (r *Runtime) PackReceiveWithAuthVRS(a *Authorization, signature []byte) ([]byte, error) {
+	return r.packAuthVRS(receiveWithAuthVRSSig, a, signature)
+}
+
+// PackCancelAuth builds calldata for cancelAuthorization(authorizer, nonce, bytes signature).
+func (r *Runtime) PackCancelAuth(authorizer ethgo.Address, nonce ethgo.Hash, signature []byte) ([]byte, error) {
+	m := r.a.MethodsBySignature[cancelAuthSig]
+	if m == nil {
+		return nil, errors.New("method not found: " + cancelAuthSig)
+	}
+	return m.Encode([]interface{}{authorizer, nonce, signature})
+}
+
+// PackCancelAuthVRS builds calldata for cancelAuthorization(authorizer, nonce, v, r, s).
+func (r *Runtime) PackCancelAuthVRS(authorizer ethgo.Address, nonce ethgo.Hash, signature []byte) ([]byte, error) {
+	m := r.a.MethodsBySignature[cancelAuthVRSSig]
+	if m == nil {
+		return nil, errors.New("method not found: " + cancelAuthVRSSig)
+	}
+	v, rr, ss, err := utils.SplitSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]interface{}{authorizer, nonce, v, rr, ss})
+}
+
+// PackAuthorizationState builds calldata for authorizationState(authorizer, nonce).
+func (r *Runtime) PackAuthorizationState(authorizer ethgo.Address, nonce ethgo.Hash) ([]byte, error) {
+	m := r.a.Methods["authorizationState"]
+	if m == nil {
+		return nil, errors.New("method not found: authorizationState")
+	}
+	return m.Encode([]interface{}{authorizer, nonce})
+}
+
+func (r *Runtime) packAuthVRS(sig string, a *Authorization, signature []byte) ([]byte, error) {
+	m := r.a.MethodsBySignature[sig]
+	if m == nil {
+		return nil, errors.New("method not found: " + sig)
+	}
+	v, rr, ss, err := utils.SplitSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]interface{}{
+		a.From, a.To, a.Value, a.ValidAfter, a.ValidBefore, a.Nonce, v, rr, ss,
+	})
+}
+
+// ------------------------- Decode (call outputs) ------------------------------
+
+// DecodeAuthorizationState decodes authorizationState output (true = used or canceled).
+func (r *Runtime) DecodeAuthorizationState(outputHex string) (bool, error) {
+	b, err := utils.HexToBytes(outputHex)
+	if err != nil {
+		return false, err
+	}
+	m := r.a.Methods["authorizationState"]
+	if m == nil {
+		return false, errors.New("method not found: authorizationState")
+	}
+	out, err := m.Decode(b)
+	if err != nil {
+		return false, err
+	}
+	used, ok := out["used"].(bool)
+	if !ok {
+		return false, errors.New("unexpected output type (want bool)")
+	}
+	return used, nil
+}
+
+// AuthorizationState reads authorizationState(authorizer, nonce) from token.
+func (r *Runtime) AuthorizationState(c *client.Client, token, authorizer ethgo.Address, nonce ethgo.Hash) (bool, error) {
+	data, err := r.PackAuthorizationState(authorizer, nonce)
+	if err != nil {
+		return false, err
+	}
+	out, err := c.Call(&client.CallMsg{To: &token, Data: data}, ethgo.Latest)
+	if err != nil {
+		return false, err
+	}
+	return r.DecodeAuthorizationState(out)
+}
+
+// ------------------------- Events (logs) -------------------------------------
+
+var (
+	// keccak256("AuthorizationUsed(address,bytes32)")
+	authorizationUsedSig = ethgo.HexToHash("0x98de503528ee59b575ef0c0a2576a82497bfc029a5685b209e9ec333479b10a5")
+
+	// keccak256("AuthorizationCanceled(address,bytes32)")
+	authorizationCanceledSig = ethgo.HexToHash("0x1cdd46ff242716cdaa72d159d339a485b3438398348d68f09d7c8c0a59353d81")
+)
+
+type AuthorizationUsedEvent struct {
+	Authorizer ethgo.Address
+	Nonce      ethgo.Hash
+}
+
+type AuthorizationCanceledEvent struct {
+	Authorizer ethgo.Address
+	Nonce      ethgo.Hash
+}
+
+func (r *Runtime) DecodeAuthorizationUsed(topics []ethgo.Hash, data []byte) (*AuthorizationUsedEvent, error) {
+	// topics[0]=sig, [1]=authorizer, [2]=nonce ; no data
+	if len(topics) < 3 {
+		return nil, errors.New("AuthorizationUsed: need 3 topics")
+	}
+	if !bytes.Equal(topics[0].Bytes(), authorizationUsedSig.Bytes()) {
+		return nil, errors.New("AuthorizationUsed: topic[0] mismatch")
+	}
+	return &AuthorizationUsedEvent{Authorizer: utils.TopicToAddress(topics[1]), Nonce: topics[2]}, nil
+}
+
+func (r *Runtime) DecodeAuthorizationCanceled(topics []ethgo.Hash, data []byte) (*AuthorizationCanceledEvent, error) {
+	// topics[0]=sig, [1]=authorizer, [2]=nonce ; no data
+	if len(topics) < 3 {
+		return nil, errors.New("AuthorizationCanceled: need 3 topics")
+	}
+	if !bytes.Equal(topics[0].Bytes(), authorizationCanceledSig.Bytes()) {
+		return nil, errors.New("AuthorizationCanceled: topic[0] mismatch")
+	}
+	return &AuthorizationCanceledEvent{Authorizer: utils.TopicToAddress(topics[1]), Nonce: topics[2]}, nil
+}
@@ -25,6 +25,109 @@ const RawABI = `[
     "outputs": [],
     "stateMutability": "nonpayable"
   },
+  {
+    "name": "transferWithAuthorization",
+    "type": "function",
+    "inputs": [
+      { "name": "from", "type": "address" },
+      { "name": "to", "type": "address" },
+      { "name": "value", "type": "uint256" },
+      { "name": "validAfter", "type": "uint256" },
+      { "name": "validBefore", "type": "uint256" },
+      { "name": "nonce", "type": "bytes32" },
+      { "name": "v", "type": "uint8" },
+      { "name": "r", "type": "bytes32" },
+      { "name": "s", "type": "bytes32" }
+    ],
+    "outputs": [],
+    "stateMutability": "nonpayable"
+  },
+  {
+    "name": "receiveWithAuthorization",
+    "type": "function",
+    "inputs": [
+      { "name": "from", "type": "address" },
+      { "name": "to", "type": "address" },
+      { "name": "value", "type": "uint256" },
+      { "name": "validAfter", "type": "uint256" },
+      { "name": "validBefore", "type": "uint256" },
+      { "name": "nonce", "type": "bytes32" },
+      { "name": "signature", "type": "bytes" }
+    ],
+    "outputs": [],
+    "stateMutability": "nonpayable"
+  },
+  {
+    "name": "receiveWithAuthorization",
+    "type": "function",
+    "inputs": [
+      { "name": "from", "type": "address" },
+      { "name": "to", "type": "address" },
+      { "name": "value", "type": "uint256" },
+      { "name": "validAfter", "type": "uint256" },
+      { "name": "validBefore", "type": "uint256" },
+      { "name": "nonce", "type": "bytes32" },
+      { "name": "v", "type": "uint8" },
+      { "name": "r", "type": "bytes32" },
+      { "name": "s", "type": "bytes32" }
+    ],
+    "outputs": [],
+    "stateMutability": "nonpayable"
+  },
+  {
+    "name": "cancelAuthorization",
+    "type": "function",
+    "inputs": [
+      { "name": "authorizer", "type": "address" },
+      { "name": "nonce", "type": "bytes32" },
+      { "name": "signature", "type": "bytes" }
+    ],
+    "outputs": [],
+    "stateMutability": "nonpayable"
+  },
+  {
+    "name": "cancelAuthorization",
+    "type": "function",
+    "inputs": [
+      { "name": "authorizer", "type": "address" },
+      { "name": "nonce", "type": "bytes32" },
+      { "name": "v", "type": "uint8" },
+      { "name": "r", "type": "bytes32" },
+      { "name": "s", "type": "bytes32" }
+    ],
+    "outputs": [],
+    "stateMutability": "nonpayable"
+  },
+  {
+    "name": "authorizationState",
+    "type": "function",
+    "inputs": [
+      { "name": "authorizer", "type": "address" },
+      { "name": "nonce", "type": "bytes32" }
+    ],
+    "outputs": [
+      { "name": "used", "type": "bool" }
+    ],
+    "stateMutability": "view"
+  },
+  {
+    "name": "AuthorizationUsed",
+    "type": "event",
+    "anonymous": false,
+    "inputs": [
+      { "indexed": true, "name": "authorizer", "type": "address" },
+      { "indexed": true, "name": "nonce", "type": "bytes32" }
+    ]
+  },
+  {
+    "name": "AuthorizationCanceled",
+    "type": "event",
+    "anonymous": false,
+    "inputs": [
+      { "indexed": true, "name": "authorizer", "type": "address" },
+      { "indexed": true, "name": "nonce", "type": "bytes32" }
+    ]
+  },
   {
     "name": "balanceOf",
     "type": "function",
@@ -50,6 +153,11 @@ func New() (*Runtime, error) {
 	return &Runtime{a: a}, nil
 }
 
+// ABI returns the parsed ABI. It declares no custom errors: EIP-3009
+// tokens such as USDC revert with Error(string) reasons, which
+// client.DecodeRevert reads without registration.
+func (r *Runtime) ABI() *abi.ABI { return r.a }
+
 // ------------------------- Pack (tx data) ------------------------------------
 
 // PackTransferWithAuth builds calldata for transferWithAuthorization(...).
@@ -0,0 +1,164 @@
+package eip3009
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/gosunuts/ethtxbuilder/transaction"
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+)
+
+const (
+	testPrivKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testFrom    = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+)
+
+var usdcDomain = transaction.Domain{
+	Name:              "USD Coin",
+	Version:           "2",
+	ChainID:           big.NewInt(1),
+	VerifyingContract: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+}
+
+func testAuthorization() *Authorization {
+	return &Authorization{
+		From:        ethgo.HexToAddress(testFrom),
+		To:          ethgo.HexToAddress("0x000000000000000000000000000000000000dEaD"),
+		Value:       big.NewInt(1e6),
+		ValidAfter:  big.NewInt(0),
+		ValidBefore: big.NewInt(1893456000),
+		Nonce:       ethgo.HexToHash("0x" + hex.EncodeToString(utils.Keccak([]byte("nonce")))),
+	}
+}
+
+func selector(sig string) string { return hex.EncodeToString(utils.Keccak([]byte(sig))[:4]) }
+
+func TestTypedData(t *testing.T) {
+	a := testAuthorization()
+	sign := utils.NewRawPrivateSigner(testPrivKey)
+
+	// Type hashes from the EIP-3009 specification.
+	for _, test := range []struct {
+		td       *transaction.TypedData
+		typeHash string
+		sign     func() ([]byte, error)
+	}{
+		{a.TransferTypedData(usdcDomain), "7c7c6cdb67a18743f49ec6fa9b35f50d52ed05cbed4cc592e13b44501c1a2267",
+			func() ([]byte, error) { return a.SignTransfer(usdcDomain, sign) }},
+		{a.ReceiveTypedData(usdcDomain), "d099cc98ef71107a616c4f0f941f04c322d8e254fe26b3c6668db87aae413de8",
+			func() ([]byte, error) { return a.SignReceive(usdcDomain, sign) }},
+		{CancelTypedData(usdcDomain, a.From, a.Nonce), "158b0a9edf7a828aad02f63cd515c68ef2f50ba807396f6d12842833a1597429",
+			func() ([]byte, error) { return SignCancel(usdcDomain, a.From, a.Nonce, sign) }},
+	} {
+		require.Equal(t, test.typeHash, hex.EncodeToString(test.td.TypeHash(test.td.PrimaryType)), test.td.PrimaryType)
+
+		digest, err := test.td.HashTypedData()
+		require.NoError(t, err)
+		sig, err := test.sign()
+		require.NoError(t, err)
+		require.Contains(t, []byte{27, 28}, sig[64])
+		addr, err := utils.RecoverAddress(digest, sig)
+		require.NoError(t, err)
+		require.Equal(t, testFrom, addr, test.td.PrimaryType)
+	}
+
+	// Transfer and receive authorizations of the same payload differ.
+	d1, err := a.TransferTypedData(usdcDomain).HashTypedData()
+	require.NoError(t, err)
+	d2, err := a.ReceiveTypedData(usdcDomain).HashTypedData()
+	require.NoError(t, err)
+	require.NotEqual(t, d1, d2)
+}
+
+func TestPackOverloads(t *testing.T) {
+	r, err := New()
+	require.NoError(t, err)
+	a := testAuthorization()
+	sig, err := a.SignTransfer(usdcDomain, utils.NewRawPrivateSigner(testPrivKey))
+	require.NoError(t, err)
+	v, rr, ss, err := utils.SplitSignature(sig)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name string
+		pack func() ([]byte, error)
+		sig  string
+		size int // argument words, excluding the dynamic signature bytes
+	}{
+		{"transfer bytes", func() ([]byte, error) {
+			return r.PackTransferWithAuth(a.From, a.To, a.Value, a.ValidAfter, a.ValidBefore, a.Nonce, sig)
+		}, "transferWithAuthorization(address,address,uint256,uint256,uint256,bytes32,bytes)", 7},
+		{"transfer vrs", func() ([]byte, error) { return r.PackTransferWithAuthVRS(a, sig) },
+			"transferWithAuthorization(address,address,uint256,uint256,uint256,bytes32,uint8,bytes32,bytes32)", 9},
+		{"receive bytes", func() ([]byte, error) { return r.PackReceiveWithAuth(a, sig) },
+			"receiveWithAuthorization(address,address,uint256,uint256,uint256,bytes32,bytes)", 7},
+		{"receive vrs", func() ([]byte, error) { return r.PackReceiveWithAuthVRS(a, sig) },
+			"receiveWithAuthorization(address,address,uint256,uint256,uint256,bytes32,uint8,bytes32,bytes32)", 9},
+		{"cancel bytes", func() ([]byte, error) { return r.PackCancelAuth(a.From, a.Nonce, sig) },
+			"cancelAuthorization(address,bytes32,bytes)", 3},
+		{"cancel vrs", func() ([]byte, error) { return r.PackCancelAuthVRS(a.From, a.Nonce, sig) },
+			"cancelAuthorization(address,bytes32,uint8,bytes32,bytes32)", 5},
+	} {
+		data, err := test.pack()
+		require.NoError(t, err, test.name)
+		require.Equal(t, selector(test.sig), hex.EncodeToString(data[:4]), test.name)
+
+		body := data[4:]
+		if test.size == 9 || test.size == 5 {
+			// v, r, s are the last three static words.
+			n := len(body)
+			require.Equal(t, test.size*32, n, test.name)
+			require.Equal(t, v, body[n-65], test.name)
+			require.Equal(t, rr[:], body[n-64:n-32], test.name)
+			require.Equal(t, ss[:], body[n-32:], test.name)
+		} else {
+			// head words, then length-prefixed 65 bytes padded to 96.
+			require.Equal(t, test.size*32+32+96, len(body), test.name)
+			require.Equal(t, sig, body[test.size*32+32:test.size*32+32+65], test.name)
+		}
+	}
+
+	_, err = r.PackTransferWithAuthVRS(a, sig[:64])
+	require.Error(t, err)
+}
+
+func TestEvents(t *testing.T) {
+	r, err := New()
+	require.NoError(t, err)
+	require.Equal(t, utils.Keccak([]byte("AuthorizationUsed(address,bytes32)")), authorizationUsedSig.Bytes())
+	require.Equal(t, utils.Keccak([]byte("AuthorizationCanceled(address,bytes32)")), authorizationCanceledSig.Bytes())
+
+	a := testAuthorization()
+	topics := []ethgo.Hash{authorizationUsedSig, ethgo.BytesToHash(a.From.Bytes()), a.Nonce}
+	used, err := r.DecodeAuthorizationUsed(topics, nil)
+	require.NoError(t, err)
+	require.Equal(t, a.From, used.Authorizer)
+	require.Equal(t, a.Nonce, used.Nonce)
+
+	_, err = r.DecodeAuthorizationCanceled(topics, nil)
+	require.Error(t, err, "topic[0] of another event")
+
+	topics[0] = authorizationCanceledSig
+	canceled, err := r.DecodeAuthorizationCanceled(topics, nil)
+	require.NoError(t, err)
+	require.Equal(t, a.From, canceled.Authorizer)
+
+	_, err = r.DecodeAuthorizationUsed(topics[:2], nil)
+	require.Error(t, err)
+}
+
+func TestAuthorizationState(t *testing.T) {
+	r, err := New()
+	require.NoError(t, err)
+	a := testAuthorization()
+	data, err := r.PackAuthorizationState(a.From, a.Nonce)
+	require.NoError(t, err)
+	require.Equal(t, selector("authorizationState(address,bytes32)"), hex.EncodeToString(data[:4]))
+
+	used, err := r.DecodeAuthorizationState("0x" + hex.EncodeToString(utils.LeftPad32([]byte{1})))
+	require.NoError(t, err)
+	require.True(t, used)
+}
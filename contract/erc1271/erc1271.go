@@ -0,0 +1,57 @@
+package erc1271
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+const RawABI = `[
+  {"name":"isValidSignature","type":"function","stateMutability":"view","inputs":[{"name":"hash","type":"bytes32"},{"name":"signature","type":"bytes"}],"outputs":[{"name":"magicValue","type":"bytes4"}]}
+]`
+
+// MagicValue is bytes4(keccak256("isValidSignature(bytes32,bytes)")).
+var MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+type Runtime struct {
+	a *abi.ABI
+}
+
+func New() (*Runtime, error) {
+	a, err := abi.NewABI(RawABI)
+	if err != nil {
+		return nil, err
+	}
+	return &Runtime{a: a}, nil
+}
+
+/* ----------------------------- Pack (tx data) ------------------------------ */
+
+// PackIsValidSignature builds calldata for isValidSignature(hash, signature).
+func (r *Runtime) PackIsValidSignature(hash ethgo.Hash, signature []byte) ([]byte, error) {
+	m := r.a.Methods["isValidSignature"]
+	if m == nil {
+		return nil, errors.New("method not found: isValidSignature")
+	}
+	return m.Encode([]any{hash, signature})
+}
+
+/* ---------------------------- Decode (call outs) --------------------------- */
+
+// DecodeIsValidSignature reports whether the eth_call output equals MagicValue.
+func (r *Runtime) DecodeIsValidSignature(outputHex string) (bool, error) {
+	b, err := utils.HexToBytes(outputHex)
+	if err != nil {
+		return false, err
+	}
+	return isMagic(b), nil
+}
+
+// isMagic checks the first returned word without relying on strict ABI
+// decoding; some wallets return non-canonical padding.
+func isMagic(ret []byte) bool {
+	return len(ret) >= 4 && bytes.Equal(ret[:4], MagicValue[:])
+}
@@ -0,0 +1,224 @@
+package erc1271
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+const (
+	testPrivKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testEOA     = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+)
+
+var (
+	account = ethgo.HexToAddress("0x00000000000000000000000000000000000a11ce")
+	factory = ethgo.HexToAddress("0x00000000000000000000000000000000000fac70")
+	magic   = "0x" + hex.EncodeToString(MagicValue[:]) + strings.Repeat("0", 56)
+)
+
+// newNode serves eth_getCode from code and answers eth_call with call(to, data),
+// which returns a hex result or a JSON-RPC error object.
+func newNode(t *testing.T, code map[ethgo.Address]string, call func(to *ethgo.Address, data []byte) (string, *codec.ErrorObject)) *client.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req codec.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		var params []json.RawMessage
+		if len(req.Params) > 0 {
+			require.NoError(t, json.Unmarshal(req.Params, &params))
+		}
+		res := codec.Response{ID: req.ID}
+		switch req.Method {
+		case "eth_chainId":
+			res.Result = json.RawMessage(`"0x1"`)
+		case "eth_getCode":
+			var addr ethgo.Address
+			require.NoError(t, json.Unmarshal(params[0], &addr))
+			c := code[addr]
+			if c == "" {
+				c = "0x"
+			}
+			res.Result = json.RawMessage(`"` + c + `"`)
+		case "eth_call":
+			var msg struct {
+				To   *ethgo.Address `json:"to"`
+				Data string         `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(params[0], &msg))
+			data, err := utils.FromHex(msg.Data)
+			require.NoError(t, err)
+			out, rpcErr := call(msg.To, data)
+			if rpcErr != nil {
+				res.Error = rpcErr
+			} else {
+				res.Result = json.RawMessage(`"` + out + `"`)
+			}
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	t.Cleanup(srv.Close)
+	c, err := client.NewClient(srv.URL)
+	require.NoError(t, err)
+	return c
+}
+
+func testHash() []byte { return utils.Keccak([]byte("hello")) }
+
+func TestVerifyEOA(t *testing.T) {
+	c := newNode(t, nil, func(*ethgo.Address, []byte) (string, *codec.ErrorObject) {
+		t.Error("eth_call for an EOA")
+		return "", nil
+	})
+	v, err := NewVerifier(c)
+	require.NoError(t, err)
+
+	sig, err := utils.NewRawPrivateSigner(testPrivKey)(testHash())
+	require.NoError(t, err)
+
+	ok, err := v.IsValidSignature(testEOA, testHash(), sig)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = v.IsValidSignature("0x000000000000000000000000000000000000dEaD", testHash(), sig)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = v.IsValidSignature(testEOA, testHash()[:31], sig)
+	require.ErrorIs(t, err, utils.ErrBadHash)
+}
+
+func TestVerify1271(t *testing.T) {
+	r, err := New()
+	require.NoError(t, err)
+	good, bad, reverting := []byte("good"), []byte("bad"), []byte("revert")
+
+	c := newNode(t, map[ethgo.Address]string{account: "0x6080"}, func(to *ethgo.Address, data []byte) (string, *codec.ErrorObject) {
+		require.Equal(t, account, *to)
+		for sig, out := range map[string]string{string(good): magic, string(bad): "0xffffffff" + strings.Repeat("0", 56)} {
+			want, err := r.PackIsValidSignature(ethgo.BytesToHash(testHash()), []byte(sig))
+			require.NoError(t, err)
+			if bytes.Equal(data, want) {
+				return out, nil
+			}
+		}
+		want, err := r.PackIsValidSignature(ethgo.BytesToHash(testHash()), reverting)
+		require.NoError(t, err)
+		if bytes.Equal(data, want) {
+			return "", &codec.ErrorObject{Code: 3, Message: "execution reverted"}
+		}
+		return "", &codec.ErrorObject{Code: -32000, Message: "header not found"}
+	})
+	v, err := NewVerifier(c)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		sig  []byte
+		want bool
+	}{
+		{good, true},
+		{bad, false},
+		{reverting, false}, // a revert is an invalid signature, not an error
+	} {
+		ok, err := v.IsValidSignature(account.String(), testHash(), test.sig)
+		require.NoError(t, err, string(test.sig))
+		require.Equal(t, test.want, ok, string(test.sig))
+	}
+
+	// Other node errors are returned.
+	_, err = v.IsValidSignature(account.String(), testHash(), []byte("other"))
+	require.Error(t, err)
+}
+
+func TestERC6492Wrapping(t *testing.T) {
+	wrapped, err := WrapERC6492Signature(factory, []byte{0xde, 0xad}, []byte("inner"))
+	require.NoError(t, err)
+	require.True(t, IsERC6492Signature(wrapped))
+	require.False(t, IsERC6492Signature([]byte("inner")))
+
+	cf, err := ParseERC6492Signature(wrapped)
+	require.NoError(t, err)
+	require.Equal(t, factory, cf.Factory)
+	require.Equal(t, []byte{0xde, 0xad}, cf.FactoryCalldata)
+	require.Equal(t, []byte("inner"), cf.Signature)
+
+	_, err = ParseERC6492Signature([]byte("inner"))
+	require.Error(t, err)
+}
+
+func TestVerify6492(t *testing.T) {
+	r, err := New()
+	require.NoError(t, err)
+	deploy := []byte{0x12, 0x34, 0x56, 0x78}
+	wrapped, err := WrapERC6492Signature(factory, deploy, []byte("good"))
+	require.NoError(t, err)
+	validate, err := r.PackIsValidSignature(ethgo.BytesToHash(testHash()), []byte("good"))
+	require.NoError(t, err)
+
+	// Counterfactual: the validator runs as init code, without a "to".
+	var calls int
+	c := newNode(t, nil, func(to *ethgo.Address, data []byte) (string, *codec.ErrorObject) {
+		calls++
+		require.Nil(t, to)
+		want, err := counterfactualValidatorCode(factory, account, deploy, validate)
+		require.NoError(t, err)
+		require.Equal(t, want, data)
+		require.True(t, bytes.HasSuffix(data, append(append([]byte{}, deploy...), validate...)))
+		require.True(t, bytes.Contains(data, append([]byte{opPUSH20}, factory[:]...)))
+		require.True(t, bytes.Contains(data, append([]byte{opPUSH20}, account[:]...)))
+		return magic, nil
+	})
+	v, err := NewVerifier(c)
+	require.NoError(t, err)
+	ok, err := v.IsValidSignature(account.String(), testHash(), wrapped)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1, calls)
+
+	// Already deployed: the inner signature goes through ERC-1271.
+	c = newNode(t, map[ethgo.Address]string{account: "0x6080"}, func(to *ethgo.Address, data []byte) (string, *codec.ErrorObject) {
+		require.Equal(t, account, *to)
+		require.Equal(t, validate, data)
+		return magic, nil
+	})
+	v, err = NewVerifier(c)
+	require.NoError(t, err)
+	ok, err = v.IsValidSignature(account.String(), testHash(), wrapped)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// A reverting deployment or check is an invalid signature.
+	c = newNode(t, nil, func(*ethgo.Address, []byte) (string, *codec.ErrorObject) {
+		return "", &codec.ErrorObject{Code: 3, Message: "execution reverted", Data: "0x"}
+	})
+	v, err = NewVerifier(c)
+	require.NoError(t, err)
+	ok, err = v.IsValidSignature(account.String(), testHash(), wrapped)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestCounterfactualValidatorCode(t *testing.T) {
+	code, err := counterfactualValidatorCode(factory, account, []byte{1, 2}, []byte{3, 4, 5})
+	require.NoError(t, err)
+	n := len(code) - 5
+	// PUSH2 payload length, PUSH2 payload offset, PUSH1 0, CODECOPY
+	require.Equal(t, []byte{opPUSH2, 0, 5, opPUSH2, byte(n >> 8), byte(n), opPUSH1, 0, opCODECOPY}, code[:9])
+	require.Equal(t, []byte{opJUMPDEST, opPUSH1, 0, opPUSH1, 0, opREVERT}, code[n-6:n])
+	require.Equal(t, fmt.Sprintf("%x", []byte{1, 2, 3, 4, 5}), fmt.Sprintf("%x", code[n:]))
+
+	_, err = counterfactualValidatorCode(factory, account, make([]byte, 0x10000), nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,139 @@
+package erc1271
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// ERC6492MagicSuffix terminates every ERC-6492 wrapped signature.
+var ERC6492MagicSuffix = ethgo.HexToHash("0x6492649264926492649264926492649264926492649264926492649264926492")
+
+// abi.encode(address create2Factory, bytes factoryCalldata, bytes originalSig)
+var erc6492Wrapper = abi.MustNewType("tuple(address factory, bytes factoryCalldata, bytes signature)")
+
+// CounterfactualSignature is the unwrapped form of an ERC-6492 signature.
+type CounterfactualSignature struct {
+	Factory         ethgo.Address
+	FactoryCalldata []byte
+	Signature       []byte
+}
+
+// IsERC6492Signature reports whether sig ends with the ERC-6492 magic suffix.
+func IsERC6492Signature(sig []byte) bool {
+	return len(sig) >= 32 && bytes.Equal(sig[len(sig)-32:], ERC6492MagicSuffix[:])
+}
+
+// ParseERC6492Signature unwraps an ERC-6492 signature.
+func ParseERC6492Signature(sig []byte) (*CounterfactualSignature, error) {
+	if !IsERC6492Signature(sig) {
+		return nil, errors.New("erc6492: missing magic suffix")
+	}
+	out, err := erc6492Wrapper.Decode(sig[:len(sig)-32])
+	if err != nil {
+		return nil, fmt.Errorf("erc6492: %w", err)
+	}
+	m, ok := out.(map[string]any)
+	if !ok {
+		return nil, errors.New("erc6492: unexpected decode result")
+	}
+	factory, ok1 := m["factory"].(ethgo.Address)
+	calldata, ok2 := m["factoryCalldata"].([]byte)
+	inner, ok3 := m["signature"].([]byte)
+	if !ok1 || !ok2 || !ok3 {
+		return nil, errors.New("erc6492: unexpected field types")
+	}
+	return &CounterfactualSignature{
+		Factory:         factory,
+		FactoryCalldata: calldata,
+		Signature:       inner,
+	}, nil
+}
+
+// WrapERC6492Signature builds an ERC-6492 signature for a not-yet-deployed account.
+func WrapERC6492Signature(factory ethgo.Address, factoryCalldata, sig []byte) ([]byte, error) {
+	enc, err := erc6492Wrapper.Encode(map[string]any{
+		"factory":         factory,
+		"factoryCalldata": factoryCalldata,
+		"signature":       sig,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(enc, ERC6492MagicSuffix[:]...), nil
+}
+
+/* ----------------------- Counterfactual validator code ---------------------- */
+
+// EVM opcodes used by counterfactualValidatorCode.
+const (
+	opPOP            = 0x50
+	opISZERO         = 0x15
+	opCODECOPY       = 0x39
+	opRETURNDATASIZE = 0x3d
+	opRETURNDATACOPY = 0x3e
+	opJUMPI          = 0x57
+	opGAS            = 0x5a
+	opJUMPDEST       = 0x5b
+	opPUSH1          = 0x60
+	opPUSH2          = 0x61
+	opPUSH20         = 0x73
+	opCALL           = 0xf1
+	opRETURN         = 0xf3
+	opSTATICCALL     = 0xfa
+	opREVERT         = 0xfd
+)
+
+// counterfactualValidatorCode assembles init code that, when executed by an
+// eth_call without a "to" address, calls the factory to deploy the account,
+// then staticcalls account.isValidSignature and returns its raw output.
+// Nothing is persisted: the whole run lives inside a single eth_call.
+//
+// Memory layout after CODECOPY: [0, n1) factory calldata, [n1, n1+n2) validate calldata.
+func counterfactualValidatorCode(factory, account ethgo.Address, factoryCalldata, validateCalldata []byte) ([]byte, error) {
+	n1, n2 := len(factoryCalldata), len(validateCalldata)
+	if n1+n2 > 0xffff {
+		return nil, errors.New("erc6492: calldata too large")
+	}
+	push1 := func(b byte) []byte { return []byte{opPUSH1, b} }
+	push2 := func(n int) []byte { return []byte{opPUSH2, byte(n >> 8), byte(n)} }
+	push20 := func(a ethgo.Address) []byte { return append([]byte{opPUSH20}, a[:]...) }
+
+	var c []byte
+	emit := func(parts ...[]byte) {
+		for _, p := range parts {
+			c = append(c, p...)
+		}
+	}
+
+	// The code length is fixed, so payload and jump offsets are known upfront.
+	const codeLen = 3 + 3 + 2 + 1 + // codecopy
+		2 + 2 + 3 + 2 + 2 + 21 + 1 + 1 + 1 + // call factory; pop
+		2 + 2 + 3 + 3 + 21 + 1 + 1 + // staticcall account
+		1 + 3 + 1 + // iszero; jumpi
+		1 + 2 + 2 + 1 + 1 + 2 + 1 + // returndatacopy; return
+		1 + 2 + 2 + 1 // jumpdest; revert
+	const revertAt = codeLen - 6
+
+	emit(push2(n1+n2), push2(codeLen), push1(0), []byte{opCODECOPY})
+
+	emit(push1(0), push1(0), push2(n1), push1(0), push1(0), push20(factory), []byte{opGAS, opCALL, opPOP})
+
+	emit(push1(0x20), push1(0), push2(n2), push2(n1), push20(account), []byte{opGAS, opSTATICCALL})
+
+	emit([]byte{opISZERO}, push2(revertAt), []byte{opJUMPI})
+
+	emit([]byte{opRETURNDATASIZE}, push1(0), push1(0), []byte{opRETURNDATACOPY, opRETURNDATASIZE}, push1(0), []byte{opRETURN})
+
+	emit([]byte{opJUMPDEST}, push1(0), push1(0), []byte{opREVERT})
+
+	if len(c) != codeLen || c[revertAt] != opJUMPDEST {
+		return nil, errors.New("erc6492: validator code layout mismatch")
+	}
+	c = append(c, factoryCalldata...)
+	c = append(c, validateCalldata...)
+	return c, nil
+}
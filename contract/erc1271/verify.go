@@ -0,0 +1,119 @@
+package erc1271
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+)
+
+// Verifier checks signatures from EOAs (ecrecover), deployed smart accounts
+// (ERC-1271) and counterfactual smart accounts (ERC-6492).
+type Verifier struct {
+	c *client.Client
+	r *Runtime
+}
+
+func NewVerifier(c *client.Client) (*Verifier, error) {
+	r, err := New()
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{c: c, r: r}, nil
+}
+
+// IsValidSignature reports whether sig is a valid signature of hash by signer.
+//
+// Order follows ERC-6492: wrapped signatures are checked first, then
+// ERC-1271 if signer has code, and finally ecrecover.
+func (v *Verifier) IsValidSignature(signer string, hash []byte, sig []byte) (bool, error) {
+	if len(hash) != utils.HashLength {
+		return false, utils.ErrBadHash
+	}
+	h := ethgo.BytesToHash(hash)
+
+	if IsERC6492Signature(sig) {
+		cf, err := ParseERC6492Signature(sig)
+		if err != nil {
+			return false, err
+		}
+		code, err := v.c.CodeAt(signer, ethgo.Latest)
+		if err != nil {
+			return false, err
+		}
+		if len(code) > 0 {
+			return v.isValid1271(signer, h, cf.Signature)
+		}
+		return v.isValidCounterfactual(signer, h, cf)
+	}
+
+	code, err := v.c.CodeAt(signer, ethgo.Latest)
+	if err != nil {
+		return false, err
+	}
+	if len(code) > 0 {
+		return v.isValid1271(signer, h, sig)
+	}
+
+	recovered, err := utils.RecoverAddress(hash, sig)
+	if err != nil {
+		if errors.Is(err, utils.ErrBadSignature) {
+			return false, nil
+		}
+		return false, err
+	}
+	return strings.EqualFold(recovered, signer), nil
+}
+
+// isValid1271 calls signer.isValidSignature(hash, sig). A revert counts as invalid.
+func (v *Verifier) isValid1271(signer string, hash ethgo.Hash, sig []byte) (bool, error) {
+	data, err := v.r.PackIsValidSignature(hash, sig)
+	if err != nil {
+		return false, err
+	}
+	to := ethgo.HexToAddress(signer)
+	out, err := v.c.Call(&client.CallMsg{To: &to, Data: data}, ethgo.Latest)
+	if err != nil {
+		if isRevert(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	b, err := utils.FromHex(out)
+	if err != nil {
+		return false, err
+	}
+	return isMagic(b), nil
+}
+
+// isValidCounterfactual simulates the factory deployment and the ERC-1271
+// check inside one eth_call, so no state is changed.
+func (v *Verifier) isValidCounterfactual(signer string, hash ethgo.Hash, cf *CounterfactualSignature) (bool, error) {
+	validate, err := v.r.PackIsValidSignature(hash, cf.Signature)
+	if err != nil {
+		return false, err
+	}
+	code, err := counterfactualValidatorCode(cf.Factory, ethgo.HexToAddress(signer), cf.FactoryCalldata, validate)
+	if err != nil {
+		return false, err
+	}
+	out, err := v.c.Call(&client.CallMsg{Data: code}, ethgo.Latest)
+	if err != nil {
+		if isRevert(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("erc6492: %w", err)
+	}
+	b, err := utils.FromHex(out)
+	if err != nil {
+		return false, err
+	}
+	return isMagic(b), nil
+}
+
+func isRevert(err error) bool {
+	return errors.Is(err, client.ErrExecutionReverted)
+}
@@ -20,6 +20,11 @@ const RawABI = `[
   {"name":"transfer","type":"function","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
   {"name":"approve","type":"function","stateMutability":"nonpayable","inputs":[{"name":"spender","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
   {"name":"transferFrom","type":"function","stateMutability":"nonpayable","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+  {"name":"version","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+  {"name":"nonces","type":"function","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"nonce","type":"uint256"}]},
+  {"name":"DOMAIN_SEPARATOR","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"bytes32"}]},
+  {"name":"permit","type":"function","stateMutability":"nonpayable","inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"},{"name":"value","type":"uint256"},{"name":"deadline","type":"uint256"},{"name":"v","type":"uint8"},{"name":"r","type":"bytes32"},{"name":"s","type":"bytes32"}],"outputs":[]},
+  {"name":"permit","type":"function","stateMutability":"nonpayable","inputs":[{"name":"holder","type":"address"},{"name":"spender","type":"address"},{"name":"nonce","type":"uint256"},{"name":"expiry","type":"uint256"},{"name":"allowed","type":"bool"},{"name":"v","type":"uint8"},{"name":"r","type":"bytes32"},{"name":"s","type":"bytes32"}],"outputs":[]},
   {"anonymous":false,"name":"Transfer","type":"event","inputs":[
     {"indexed":true,"name":"from","type":"address"},
     {"indexed":true,"name":"to","type":"address"},
@@ -27,7 +32,15 @@ const RawABI = `[
   {"anonymous":false,"name":"Approval","type":"event","inputs":[
     {"indexed":true,"name":"owner","type":"address"},
     {"indexed":true,"name":"spender","type":"address"},
-    {"indexed":false,"name":"value","type":"uint256"}]}
+    {"indexed":false,"name":"value","type":"uint256"}]},
+  {"name":"ERC20InsufficientBalance","type":"error","inputs":[{"name":"sender","type":"address"},{"name":"balance","type":"uint256"},{"name":"needed","type":"uint256"}]},
+  {"name":"ERC20InvalidSender","type":"error","inputs":[{"name":"sender","type":"address"}]},
+  {"name":"ERC20InvalidReceiver","type":"error","inputs":[{"name":"receiver","type":"address"}]},
+  {"name":"ERC20InsufficientAllowance","type":"error","inputs":[{"name":"spender","type":"address"},{"name":"allowance","type":"uint256"},{"name":"needed","type":"uint256"}]},
+  {"name":"ERC20InvalidApprover","type":"error","inputs":[{"name":"approver","type":"address"}]},
+  {"name":"ERC20InvalidSpender","type":"error","inputs":[{"name":"spender","type":"address"}]},
+  {"name":"ERC2612ExpiredSignature","type":"error","inputs":[{"name":"deadline","type":"uint256"}]},
+  {"name":"ERC2612InvalidSigner","type":"error","inputs":[{"name":"signer","type":"address"},{"name":"owner","type":"address"}]}
 ]`
 
 type Runtime struct {
@@ -42,6 +55,10 @@ func New() (*Runtime, error) {
 	return &Runtime{a: a}, nil
 }
 
+// ABI returns the parsed ABI, including the ERC-6093 and ERC-2612 custom
+// errors, e.g. for client.RegisterErrors.
+func (r *Runtime) ABI() *abi.ABI { return r.a }
+
 /* ----------------------------- Pack (tx data) ------------------------------ */
 
 func (r *Runtime) PackTransfer(to ethgo.Address, value *big.Int) ([]byte, error) {
@@ -89,7 +106,7 @@ func (r *Runtime) PackName() ([]byte, error) {
 	if m == nil {
 		return nil, errors.New("method not found: name")
 	}
-	return m.Encode(nil)
+	return m.ID(), nil
 }
 
 func (r *Runtime) PackSymbol() ([]byte, error) {
@@ -97,7 +114,7 @@ func (r *Runtime) PackSymbol() ([]byte, error) {
 	if m == nil {
 		return nil, errors.New("method not found: symbol")
 	}
-	return m.Encode(nil)
+	return m.ID(), nil
 }
 
 func (r *Runtime) PackDecimals() ([]byte, error) {
@@ -105,7 +122,7 @@ func (r *Runtime) PackDecimals() ([]byte, error) {
 	if m == nil {
 		return nil, errors.New("method not found: decimals")
 	}
-	return m.Encode(nil)
+	return m.ID(), nil
 }
 
 func (r *Runtime) PackTotalSupply() ([]byte, error) {
@@ -113,7 +130,7 @@ func (r *Runtime) PackTotalSupply() ([]byte, error) {
 	if m == nil {
 		return nil, errors.New("method not found: totalSupply")
 	}
-	return m.Encode(nil)
+	return m.ID(), nil
 }
 
 /* ---------------------------- Decode (call outs) --------------------------- */
@@ -0,0 +1,54 @@
+package erc20
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+)
+
+func TestRegisterErrors(t *testing.T) {
+	r, err := New()
+	require.NoError(t, err)
+	client.RegisterErrors(r.ABI())
+
+	// ERC20InsufficientBalance(address,uint256,uint256), selector 0xe450d38c.
+	owner := ethgo.HexToAddress(testOwner)
+	data := "e450d38c" + hex.EncodeToString(utils.LeftPad32(owner[:])) +
+		fmt.Sprintf("%064x%064x", 5, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var q struct {
+			ID     uint64 `json:"id"`
+			Method string `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&q))
+		if q.Method == "eth_chainId" {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":"0x1"}`, q.ID)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":{"code":3,"message":"execution reverted","data":"0x%s"}}`, q.ID, data)
+	}))
+	defer srv.Close()
+	c, err := client.NewClient(srv.URL)
+	require.NoError(t, err)
+
+	in, err := r.PackTransfer(ethgo.HexToAddress("0x02"), big.NewInt(10))
+	require.NoError(t, err)
+	_, err = c.Call(&client.CallMsg{From: owner, To: &usdc, Data: in}, ethgo.Latest)
+	require.ErrorIs(t, err, client.ErrExecutionReverted)
+	var rev *client.RevertError
+	require.ErrorAs(t, err, &rev)
+	require.Equal(t, "ERC20InsufficientBalance(address,uint256,uint256)", rev.Custom)
+	require.Equal(t, owner, rev.Args["sender"])
+	require.Equal(t, int64(5), rev.Args["balance"].(*big.Int).Int64())
+	require.Equal(t, int64(10), rev.Args["needed"].(*big.Int).Int64())
+	require.Equal(t, "execution reverted: ERC20InsufficientBalance(sender="+owner.String()+", balance=5, needed=10)", err.Error())
+}
@@ -0,0 +1,338 @@
+package erc20
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/gosunuts/ethtxbuilder/transaction"
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+)
+
+const (
+	permitSig    = "permit(address,address,uint256,uint256,uint8,bytes32,bytes32)"
+	daiPermitSig = "permit(address,address,uint256,uint256,bool,uint8,bytes32,bytes32)"
+)
+
+// Permit is an EIP-2612 approval.
+type Permit struct {
+	Owner    ethgo.Address
+	Spender  ethgo.Address
+	Value    *big.Int
+	Nonce    *big.Int
+	Deadline *big.Int
+}
+
+// DAIPermit is the pre-2612 DAI approval (all-or-nothing "allowed" flag).
+type DAIPermit struct {
+	Holder  ethgo.Address
+	Spender ethgo.Address
+	Nonce   *big.Int
+	Expiry  *big.Int
+	Allowed bool
+}
+
+// SignedPermit carries a permit and its split signature.
+type SignedPermit struct {
+	Permit
+	V    uint8
+	R, S [32]byte
+}
+
+// SignedDAIPermit carries a DAI permit and its split signature.
+type SignedDAIPermit struct {
+	DAIPermit
+	V    uint8
+	R, S [32]byte
+}
+
+/* ----------------------------- Typed data ---------------------------------- */
+
+// PermitTypedData builds the EIP-2612 Permit typed data.
+func PermitTypedData(domain transaction.Domain, p *Permit) *transaction.TypedData {
+	return &transaction.TypedData{
+		Types: transaction.Types{
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain:      domain,
+		Message: map[string]any{
+			"owner":    p.Owner.String(),
+			"spender":  p.Spender.String(),
+			"value":    p.Value,
+			"nonce":    p.Nonce,
+			"deadline": p.Deadline,
+		},
+	}
+}
+
+// DAIPermitTypedData builds the DAI-style Permit typed data.
+func DAIPermitTypedData(domain transaction.Domain, p *DAIPermit) *transaction.TypedData {
+	return &transaction.TypedData{
+		Types: transaction.Types{
+			"Permit": {
+				{Name: "holder", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "expiry", Type: "uint256"},
+				{Name: "allowed", Type: "bool"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain:      domain,
+		Message: map[string]any{
+			"holder":  p.Holder.String(),
+			"spender": p.Spender.String(),
+			"nonce":   p.Nonce,
+			"expiry":  p.Expiry,
+			"allowed": p.Allowed,
+		},
+	}
+}
+
+/* ----------------------------- Sign ---------------------------------------- */
+
+// SignPermit reads the owner's nonce and the token domain, then signs the permit.
+func (r *Runtime) SignPermit(c *client.Client, token, owner, spender ethgo.Address, value, deadline *big.Int, sign utils.SignFunc) (*SignedPermit, error) {
+	nonce, err := r.Nonces(c, token, owner)
+	if err != nil {
+		return nil, err
+	}
+	domain, err := r.PermitDomain(c, token)
+	if err != nil {
+		return nil, err
+	}
+	p := &Permit{Owner: owner, Spender: spender, Value: value, Nonce: nonce, Deadline: deadline}
+	sig, err := PermitTypedData(domain, p).Sign(sign)
+	if err != nil {
+		return nil, err
+	}
+	v, rr, ss, err := utils.SplitSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedPermit{Permit: *p, V: v, R: rr, S: ss}, nil
+}
+
+// SignDAIPermit reads the holder's nonce and the token domain, then signs the DAI permit.
+func (r *Runtime) SignDAIPermit(c *client.Client, token, holder, spender ethgo.Address, expiry *big.Int, allowed bool, sign utils.SignFunc) (*SignedDAIPermit, error) {
+	nonce, err := r.Nonces(c, token, holder)
+	if err != nil {
+		return nil, err
+	}
+	domain, err := r.PermitDomain(c, token)
+	if err != nil {
+		return nil, err
+	}
+	p := &DAIPermit{Holder: holder, Spender: spender, Nonce: nonce, Expiry: expiry, Allowed: allowed}
+	sig, err := DAIPermitTypedData(domain, p).Sign(sign)
+	if err != nil {
+		return nil, err
+	}
+	v, rr, ss, err := utils.SplitSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedDAIPermit{DAIPermit: *p, V: v, R: rr, S: ss}, nil
+}
+
+/* ----------------------------- Pack (tx data) ------------------------------ */
+
+// PackPermit builds calldata for permit(owner, spender, value, deadline, v, r, s).
+func (r *Runtime) PackPermit(p *SignedPermit) ([]byte, error) {
+	m := r.a.MethodsBySignature[permitSig]
+	if m == nil {
+		return nil, errors.New("method not found: " + permitSig)
+	}
+	return m.Encode([]any{p.Owner, p.Spender, p.Value, p.Deadline, p.V, p.R, p.S})
+}
+
+// PackDAIPermit builds calldata for permit(holder, spender, nonce, expiry, allowed, v, r, s).
+func (r *Runtime) PackDAIPermit(p *SignedDAIPermit) ([]byte, error) {
+	m := r.a.MethodsBySignature[daiPermitSig]
+	if m == nil {
+		return nil, errors.New("method not found: " + daiPermitSig)
+	}
+	return m.Encode([]any{p.Holder, p.Spender, p.Nonce, p.Expiry, p.Allowed, p.V, p.R, p.S})
+}
+
+func (r *Runtime) PackNonces(owner ethgo.Address) ([]byte, error) {
+	m := r.a.Methods["nonces"]
+	if m == nil {
+		return nil, errors.New("method not found: nonces")
+	}
+	return m.Encode([]any{owner})
+}
+
+func (r *Runtime) PackDomainSeparator() ([]byte, error) {
+	m := r.a.Methods["DOMAIN_SEPARATOR"]
+	if m == nil {
+		return nil, errors.New("method not found: DOMAIN_SEPARATOR")
+	}
+	return m.ID(), nil
+}
+
+func (r *Runtime) PackVersion() ([]byte, error) {
+	m := r.a.Methods["version"]
+	if m == nil {
+		return nil, errors.New("method not found: version")
+	}
+	return m.ID(), nil
+}
+
+/* ---------------------------- Decode (call outs) --------------------------- */
+
+func (r *Runtime) DecodeNonces(outputHex string) (*big.Int, error) {
+	return r.DecodeUint256Single(outputHex, "nonces", "nonce")
+}
+
+func (r *Runtime) DecodeVersion(outputHex string) (string, error) {
+	return r.DecodeStringSingle(outputHex, "version")
+}
+
+func (r *Runtime) DecodeDomainSeparator(outputHex string) (ethgo.Hash, error) {
+	b, err := utils.HexToBytes(outputHex)
+	if err != nil {
+		return ethgo.Hash{}, err
+	}
+	if len(b) != 32 {
+		return ethgo.Hash{}, fmt.Errorf("DOMAIN_SEPARATOR: unexpected output length %d", len(b))
+	}
+	return ethgo.BytesToHash(b), nil
+}
+
+/* ---------------------------- Reads (eth_call) ----------------------------- */
+
+// Nonces reads nonces(owner) from token.
+func (r *Runtime) Nonces(c *client.Client, token, owner ethgo.Address) (*big.Int, error) {
+	data, err := r.PackNonces(owner)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.Call(&client.CallMsg{To: &token, Data: data}, ethgo.Latest)
+	if err != nil {
+		return nil, err
+	}
+	return r.DecodeNonces(out)
+}
+
+// DomainSeparator reads DOMAIN_SEPARATOR() from token.
+func (r *Runtime) DomainSeparator(c *client.Client, token ethgo.Address) (ethgo.Hash, error) {
+	data, err := r.PackDomainSeparator()
+	if err != nil {
+		return ethgo.Hash{}, err
+	}
+	out, err := c.Call(&client.CallMsg{To: &token, Data: data}, ethgo.Latest)
+	if err != nil {
+		return ethgo.Hash{}, err
+	}
+	return r.DecodeDomainSeparator(out)
+}
+
+// Name reads name() from token.
+func (r *Runtime) Name(c *client.Client, token ethgo.Address) (string, error) {
+	data, err := r.PackName()
+	if err != nil {
+		return "", err
+	}
+	out, err := c.Call(&client.CallMsg{To: &token, Data: data}, ethgo.Latest)
+	if err != nil {
+		return "", err
+	}
+	return r.DecodeName(out)
+}
+
+// Version reads version() from token.
+func (r *Runtime) Version(c *client.Client, token ethgo.Address) (string, error) {
+	data, err := r.PackVersion()
+	if err != nil {
+		return "", err
+	}
+	out, err := c.Call(&client.CallMsg{To: &token, Data: data}, ethgo.Latest)
+	if err != nil {
+		return "", err
+	}
+	return r.DecodeVersion(out)
+}
+
+// PermitDomain builds the token's EIP-712 domain from name() and version()
+// (falling back to "1", which most 2612 tokens use without exposing version()).
+// When the token exposes DOMAIN_SEPARATOR() the result is checked against it.
+// A read counts as missing only if it reverts or returns no data; any other
+// error is returned.
+func (r *Runtime) PermitDomain(c *client.Client, token ethgo.Address) (transaction.Domain, error) {
+	name, err := r.Name(c, token)
+	if err != nil {
+		return transaction.Domain{}, err
+	}
+	version := "1"
+	data, err := r.PackVersion()
+	if err != nil {
+		return transaction.Domain{}, err
+	}
+	out, ok, err := callOptional(c, token, data)
+	if err != nil {
+		return transaction.Domain{}, err
+	}
+	if ok {
+		v, err := r.DecodeVersion(out)
+		if err != nil {
+			return transaction.Domain{}, err
+		}
+		if v != "" {
+			version = v
+		}
+	}
+	domain := transaction.Domain{
+		Name:              name,
+		Version:           version,
+		ChainID:           c.ChainId,
+		VerifyingContract: token.String(),
+	}
+
+	if data, err = r.PackDomainSeparator(); err != nil {
+		return transaction.Domain{}, err
+	}
+	out, ok, err = callOptional(c, token, data)
+	if err != nil {
+		return transaction.Domain{}, err
+	}
+	if !ok {
+		return domain, nil
+	}
+	onchain, err := r.DecodeDomainSeparator(out)
+	if err != nil {
+		return transaction.Domain{}, err
+	}
+	td := &transaction.TypedData{Types: transaction.Types{}, Domain: domain}
+	ds, err := td.DomainSeparator()
+	if err != nil {
+		return transaction.Domain{}, err
+	}
+	if !bytes.Equal(ds, onchain[:]) {
+		return transaction.Domain{}, fmt.Errorf("permit domain mismatch for %s: computed %x, on-chain %s", token, ds, onchain)
+	}
+	return domain, nil
+}
+
+// callOptional calls a read the token may not implement. ok is false when
+// the call reverts or returns no data.
+func callOptional(c *client.Client, token ethgo.Address, data []byte) (out string, ok bool, err error) {
+	out, err = c.Call(&client.CallMsg{To: &token, Data: data}, ethgo.Latest)
+	if errors.Is(err, client.ErrExecutionReverted) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return out, out != "0x" && out != "", nil
+}
@@ -0,0 +1,241 @@
+package erc20
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/gosunuts/ethtxbuilder/transaction"
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+const (
+	testPrivKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testOwner   = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+)
+
+var (
+	usdc = ethgo.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	dai  = ethgo.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F")
+
+	usdcDomain = transaction.Domain{Name: "USD Coin", Version: "2", ChainID: big.NewInt(1), VerifyingContract: usdc.String()}
+	daiDomain  = transaction.Domain{Name: "Dai Stablecoin", Version: "1", ChainID: big.NewInt(1), VerifyingContract: dai.String()}
+
+	// DOMAIN_SEPARATOR() of the mainnet contracts.
+	usdcSeparator = "06c37168a7db5138defc7866392bb87a741f9b3d104deb5094588ce041cae335"
+	daiSeparator  = "dbb8cf42e1ecb028be3f3dbc922e1d878b963f411dc388ced501601c60f7c6f7"
+)
+
+func word(v any) []byte {
+	switch x := v.(type) {
+	case ethgo.Address:
+		return utils.LeftPad32(x[:])
+	case *big.Int:
+		return utils.LeftPad32(x.Bytes())
+	case bool:
+		if x {
+			return utils.LeftPad32([]byte{1})
+		}
+		return make([]byte, 32)
+	case []byte:
+		return x
+	}
+	panic("word: unsupported type")
+}
+
+// digest hashes typeHash and the fields the way the token contracts do.
+func digest(separator string, typeHash string, fields ...any) []byte {
+	enc := utils.Keccak([]byte(typeHash))
+	for _, f := range fields {
+		enc = append(enc, word(f)...)
+	}
+	ds, _ := hex.DecodeString(separator)
+	return utils.Keccak(append(append([]byte{0x19, 0x01}, ds...), utils.Keccak(enc)...))
+}
+
+func TestPermitTypedData(t *testing.T) {
+	owner, spender := ethgo.HexToAddress(testOwner), ethgo.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+	p := &Permit{Owner: owner, Spender: spender, Value: big.NewInt(1e6), Nonce: big.NewInt(7), Deadline: big.NewInt(1893456000)}
+	td := PermitTypedData(usdcDomain, p)
+
+	require.Equal(t, "6e71edae12b1b97f4d1f60370fef10105fa2faae0126114a169c64845d6126c9", hex.EncodeToString(td.TypeHash("Permit")))
+	ds, err := td.DomainSeparator()
+	require.NoError(t, err)
+	require.Equal(t, usdcSeparator, hex.EncodeToString(ds))
+
+	h, err := td.HashTypedData()
+	require.NoError(t, err)
+	want := digest(usdcSeparator, "Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)", owner, spender, p.Value, p.Nonce, p.Deadline)
+	require.Equal(t, want, h)
+
+	// Sign -> split -> recover round trip.
+	sig, err := td.Sign(utils.NewRawPrivateSigner(testPrivKey))
+	require.NoError(t, err)
+	v, r, s, err := utils.SplitSignature(sig)
+	require.NoError(t, err)
+	require.Contains(t, []uint8{27, 28}, v)
+	addr, err := utils.RecoverAddress(h, append(append(r[:], s[:]...), v))
+	require.NoError(t, err)
+	require.Equal(t, testOwner, addr)
+}
+
+func TestDAIPermitTypedData(t *testing.T) {
+	holder, spender := ethgo.HexToAddress(testOwner), ethgo.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	p := &DAIPermit{Holder: holder, Spender: spender, Nonce: big.NewInt(0), Expiry: big.NewInt(0), Allowed: true}
+	td := DAIPermitTypedData(daiDomain, p)
+
+	require.Equal(t, "ea2aa0a1be11a07ed86d755c93467f4f82362b452371d1ba94d1715123511acb", hex.EncodeToString(td.TypeHash("Permit")))
+	ds, err := td.DomainSeparator()
+	require.NoError(t, err)
+	require.Equal(t, daiSeparator, hex.EncodeToString(ds))
+
+	h, err := td.HashTypedData()
+	require.NoError(t, err)
+	want := digest(daiSeparator, "Permit(address holder,address spender,uint256 nonce,uint256 expiry,bool allowed)", holder, spender, p.Nonce, p.Expiry, true)
+	require.Equal(t, want, h)
+}
+
+func TestPackPermit(t *testing.T) {
+	r, err := New()
+	require.NoError(t, err)
+	owner, spender := ethgo.HexToAddress(testOwner), ethgo.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	rr, ss := [32]byte{1}, [32]byte{2}
+
+	data, err := r.PackPermit(&SignedPermit{
+		Permit: Permit{Owner: owner, Spender: spender, Value: big.NewInt(5), Nonce: big.NewInt(9), Deadline: big.NewInt(100)},
+		V:      27, R: rr, S: ss,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "d505accf", hex.EncodeToString(data[:4]))
+	var want []byte
+	for _, f := range []any{owner, spender, big.NewInt(5), big.NewInt(100), big.NewInt(27), rr[:], ss[:]} {
+		want = append(want, word(f)...)
+	}
+	require.Equal(t, want, data[4:], "nonce is not part of the 2612 calldata")
+
+	data, err = r.PackDAIPermit(&SignedDAIPermit{
+		DAIPermit: DAIPermit{Holder: owner, Spender: spender, Nonce: big.NewInt(9), Expiry: big.NewInt(100), Allowed: true},
+		V:         28, R: rr, S: ss,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "8fcbaf0c", hex.EncodeToString(data[:4]))
+	want = nil
+	for _, f := range []any{owner, spender, big.NewInt(9), big.NewInt(100), true, big.NewInt(28), rr[:], ss[:]} {
+		want = append(want, word(f)...)
+	}
+	require.Equal(t, want, data[4:])
+}
+
+// newTokenNode answers name(), version(), nonces() and DOMAIN_SEPARATOR()
+// eth_calls to a token on chain 1; an empty separator reverts. A version or
+// separator starting with 0x is returned as is, and one starting with
+// {"code": is sent as a JSON-RPC error.
+func newTokenNode(t *testing.T, name, version, separator string) *client.Client {
+	r, err := New()
+	require.NoError(t, err)
+	str := func(s string) string {
+		if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, `{"code":`) {
+			return s
+		}
+		b, err := abi.Encode([]any{s}, abi.MustNewType("tuple(string)"))
+		require.NoError(t, err)
+		return "0x" + hex.EncodeToString(b)
+	}
+	sel := func(b []byte, err error) string {
+		require.NoError(t, err)
+		return hex.EncodeToString(b[:4])
+	}
+	results := map[string]string{
+		sel(r.PackName()):    str(name),
+		sel(r.PackVersion()): str(version),
+		sel(r.PackNonces(ethgo.HexToAddress(testOwner))): "0x" + strings.Repeat("0", 63) + "7",
+	}
+	if separator != "" {
+		if !strings.HasPrefix(separator, "0x") && !strings.HasPrefix(separator, `{"code":`) {
+			separator = "0x" + separator
+		}
+		results[sel(r.PackDomainSeparator())] = separator
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var q codec.Request
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&q))
+		res := codec.Response{ID: q.ID}
+		switch q.Method {
+		case "eth_chainId":
+			res.Result = json.RawMessage(`"0x1"`)
+		case "eth_call":
+			var params []json.RawMessage
+			require.NoError(t, json.Unmarshal(q.Params, &params))
+			var msg struct {
+				Data string `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(params[0], &msg))
+			out, ok := results[strings.TrimPrefix(msg.Data, "0x")[:8]]
+			switch {
+			case ok && strings.HasPrefix(out, `{"code":`):
+				res.Error = &codec.ErrorObject{}
+				require.NoError(t, json.Unmarshal([]byte(out), res.Error))
+			case ok:
+				res.Result = json.RawMessage(`"` + out + `"`)
+			default:
+				res.Error = &codec.ErrorObject{Code: 3, Message: "execution reverted"}
+			}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	t.Cleanup(srv.Close)
+	c, err := client.NewClient(srv.URL)
+	require.NoError(t, err)
+	return c
+}
+
+func TestPermitDomain(t *testing.T) {
+	r, err := New()
+	require.NoError(t, err)
+
+	c := newTokenNode(t, "USD Coin", "2", usdcSeparator)
+	domain, err := r.PermitDomain(c, usdc)
+	require.NoError(t, err)
+	require.Equal(t, "2", domain.Version)
+
+	// A separator that does not match name/version/chain/address is rejected.
+	_, err = r.PermitDomain(c, dai)
+	require.ErrorContains(t, err, "permit domain mismatch")
+
+	// Without DOMAIN_SEPARATOR() the computed domain is used unchecked.
+	c = newTokenNode(t, "Dai Stablecoin", "1", "")
+	domain, err = r.PermitDomain(c, dai)
+	require.NoError(t, err)
+	require.Equal(t, daiDomain.Name, domain.Name)
+
+	// Empty return data also counts as a missing method.
+	domain, err = r.PermitDomain(newTokenNode(t, "Dai Stablecoin", "0x", "0x"), dai)
+	require.NoError(t, err)
+	require.Equal(t, "1", domain.Version)
+
+	// Other errors are returned rather than guessed around.
+	limited := `{"code":-32005,"message":"request rate limited"}`
+	_, err = r.PermitDomain(newTokenNode(t, "USD Coin", limited, usdcSeparator), usdc)
+	require.ErrorIs(t, err, client.ErrRateLimited)
+	_, err = r.PermitDomain(newTokenNode(t, "USD Coin", "2", limited), usdc)
+	require.ErrorIs(t, err, client.ErrRateLimited)
+
+	signed, err := r.SignPermit(c, dai, ethgo.HexToAddress(testOwner), usdc, big.NewInt(1), big.NewInt(2), utils.NewRawPrivateSigner(testPrivKey))
+	require.NoError(t, err)
+	require.Equal(t, int64(7), signed.Nonce.Int64())
+	h, err := PermitTypedData(daiDomain, &signed.Permit).HashTypedData()
+	require.NoError(t, err)
+	addr, err := utils.RecoverAddress(h, append(append(signed.R[:], signed.S[:]...), signed.V))
+	require.NoError(t, err)
+	require.Equal(t, testOwner, addr)
+}
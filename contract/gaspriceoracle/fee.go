@@ -0,0 +1,187 @@
+package gaspriceoracle
+
+import (
+	"math/big"
+)
+
+// Fjord cost model constants (scaled by 1e6).
+const (
+	fjordIntercept    = -42_585_600
+	fjordFastLZCoef   = 836_500
+	fjordMinSizeScale = 100_000_000
+)
+
+// Params are the GasPriceOracle values the L1 fee is computed from.
+type Params struct {
+	L1BaseFee   *big.Int
+	BlobBaseFee *big.Int // Ecotone and later
+
+	BaseFeeScalar     uint64 // Ecotone and later
+	BlobBaseFeeScalar uint64 // Ecotone and later
+
+	Overhead *big.Int // pre-Ecotone
+	Scalar   *big.Int // pre-Ecotone
+	Decimals *big.Int // pre-Ecotone
+
+	IsEcotone bool
+	IsFjord   bool
+}
+
+// L1Fee returns the L1 data fee, in wei, of a signed transaction (the
+// EncodeRLP bytes that are sent with eth_sendRawTransaction).
+func (p *Params) L1Fee(signedTx []byte) *big.Int {
+	switch {
+	case p.IsFjord:
+		return p.fjordFee(signedTx)
+	case p.IsEcotone:
+		return p.ecotoneFee(signedTx)
+	default:
+		return p.bedrockFee(signedTx)
+	}
+}
+
+// L1GasUsed returns the L1 gas the transaction is charged for (the
+// estimated compressed size in calldata gas from Fjord on).
+func (p *Params) L1GasUsed(signedTx []byte) uint64 {
+	if p.IsFjord {
+		return fjordSize(signedTx).Uint64() * 16 / 1_000_000
+	}
+	gas := calldataGas(signedTx)
+	if !p.IsEcotone && p.Overhead != nil {
+		gas += p.Overhead.Uint64()
+	}
+	return gas
+}
+
+// bedrockFee: (calldataGas + overhead) * l1BaseFee * scalar / 10^decimals.
+func (p *Params) bedrockFee(tx []byte) *big.Int {
+	gas := new(big.Int).SetUint64(calldataGas(tx))
+	gas.Add(gas, orZero(p.Overhead))
+	fee := gas.Mul(gas, orZero(p.L1BaseFee))
+	fee.Mul(fee, orZero(p.Scalar))
+	return fee.Div(fee, new(big.Int).Exp(big.NewInt(10), orZero(p.Decimals), nil))
+}
+
+// ecotoneFee: calldataGas * (16*l1BaseFee*baseFeeScalar + blobBaseFee*blobBaseFeeScalar) / 16e6.
+func (p *Params) ecotoneFee(tx []byte) *big.Int {
+	fee := new(big.Int).SetUint64(calldataGas(tx))
+	fee.Mul(fee, p.scaledFee())
+	return fee.Div(fee, big.NewInt(16_000_000))
+}
+
+// fjordFee: estimatedSize * (16*l1BaseFee*baseFeeScalar + blobBaseFee*blobBaseFeeScalar) / 1e12.
+func (p *Params) fjordFee(tx []byte) *big.Int {
+	fee := fjordSize(tx)
+	fee.Mul(fee, p.scaledFee())
+	return fee.Div(fee, big.NewInt(1_000_000_000_000))
+}
+
+func (p *Params) scaledFee() *big.Int {
+	base := new(big.Int).Mul(orZero(p.L1BaseFee), big.NewInt(16))
+	base.Mul(base, new(big.Int).SetUint64(p.BaseFeeScalar))
+	blob := new(big.Int).Mul(orZero(p.BlobBaseFee), new(big.Int).SetUint64(p.BlobBaseFeeScalar))
+	return base.Add(base, blob)
+}
+
+// fjordSize is the estimated compressed size of tx, scaled by 1e6.
+func fjordSize(tx []byte) *big.Int {
+	size := int64(fjordIntercept) + int64(fjordFastLZCoef)*int64(FlzCompressLen(tx))
+	return big.NewInt(max(size, fjordMinSizeScale))
+}
+
+func calldataGas(b []byte) uint64 {
+	var gas uint64
+	for _, x := range b {
+		if x == 0 {
+			gas += 4
+		} else {
+			gas += 16
+		}
+	}
+	return gas
+}
+
+func orZero(x *big.Int) *big.Int {
+	if x == nil {
+		return new(big.Int)
+	}
+	return x
+}
+
+// FlzCompressLen returns the length of b after FastLZ (level 1)
+// compression, as computed by the Fjord GasPriceOracle and op-geth.
+func FlzCompressLen(b []byte) uint32 {
+	n := uint32(0)
+	ht := make([]uint32, 8192)
+	u24 := func(i uint32) uint32 {
+		return uint32(b[i]) | uint32(b[i+1])<<8 | uint32(b[i+2])<<16
+	}
+	cmp := func(p, q, e uint32) uint32 {
+		l := uint32(0)
+		for e -= q; l < e; l++ {
+			if b[p+l] != b[q+l] {
+				e = 0
+			}
+		}
+		return l
+	}
+	literals := func(r uint32) {
+		n += 0x21 * (r / 0x20)
+		r %= 0x20
+		if r != 0 {
+			n += r + 1
+		}
+	}
+	match := func(l uint32) {
+		l--
+		n += 3 * (l / 262)
+		if l%262 >= 6 {
+			n += 3
+		} else {
+			n += 2
+		}
+	}
+	hash := func(v uint32) uint32 {
+		return ((2654435769 * v) >> 19) & 0x1fff
+	}
+	setNextHash := func(ip uint32) uint32 {
+		ht[hash(u24(ip))] = ip
+		return ip + 1
+	}
+
+	a := uint32(0)
+	ipLimit := uint32(0)
+	if len(b) >= 13 {
+		ipLimit = uint32(len(b)) - 13
+	}
+	for ip := a + 2; ip < ipLimit; {
+		var r, d uint32
+		for {
+			s := u24(ip)
+			h := hash(s)
+			r = ht[h]
+			ht[h] = ip
+			d = ip - r
+			if ip >= ipLimit {
+				break
+			}
+			ip++
+			if d <= 0x1fff && s == u24(r) {
+				break
+			}
+		}
+		if ip >= ipLimit {
+			break
+		}
+		ip--
+		if ip > a {
+			literals(ip - a)
+		}
+		l := cmp(r+3, ip+3, ipLimit+9)
+		match(l)
+		ip = setNextHash(setNextHash(ip + l))
+		a = ip
+	}
+	literals(uint32(len(b)) - a)
+	return n
+}
@@ -0,0 +1,92 @@
+// Package gaspriceoracle estimates the L1 data fee of OP Stack transactions
+// from the GasPriceOracle predeploy.
+package gaspriceoracle
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// Address is the GasPriceOracle predeploy on every OP Stack chain.
+var Address = ethgo.HexToAddress("0x420000000000000000000000000000000000000F")
+
+const RawABI = `[
+  {"name":"l1BaseFee","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+  {"name":"blobBaseFee","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+  {"name":"baseFeeScalar","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint32"}]},
+  {"name":"blobBaseFeeScalar","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint32"}]},
+  {"name":"overhead","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+  {"name":"scalar","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+  {"name":"decimals","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+  {"name":"isEcotone","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"bool"}]},
+  {"name":"isFjord","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"bool"}]},
+  {"name":"getL1Fee","type":"function","stateMutability":"view","inputs":[{"name":"_data","type":"bytes"}],"outputs":[{"name":"","type":"uint256"}]},
+  {"name":"getL1FeeUpperBound","type":"function","stateMutability":"view","inputs":[{"name":"_unsignedTxSize","type":"uint256"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+type Runtime struct {
+	a *abi.ABI
+}
+
+func New() (*Runtime, error) {
+	a, err := abi.NewABI(RawABI)
+	if err != nil {
+		return nil, err
+	}
+	return &Runtime{a: a}, nil
+}
+
+func (r *Runtime) method(name string) (*abi.Method, error) {
+	m := r.a.Methods[name]
+	if m == nil {
+		return nil, errors.New("method not found: " + name)
+	}
+	return m, nil
+}
+
+/* ----------------------------- Pack (call data) ---------------------------- */
+
+// PackNoArgs returns the selector of a parameterless getter (l1BaseFee, isFjord, ...).
+func (r *Runtime) PackNoArgs(method string) ([]byte, error) {
+	m, err := r.method(method)
+	if err != nil {
+		return nil, err
+	}
+	return m.ID(), nil
+}
+
+// PackGetL1Fee builds calldata for getL1Fee(data).
+func (r *Runtime) PackGetL1Fee(data []byte) ([]byte, error) {
+	m, err := r.method("getL1Fee")
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]any{data})
+}
+
+// PackGetL1FeeUpperBound builds calldata for getL1FeeUpperBound(unsignedTxSize) (Fjord).
+func (r *Runtime) PackGetL1FeeUpperBound(unsignedTxSize uint64) ([]byte, error) {
+	m, err := r.method("getL1FeeUpperBound")
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]any{new(big.Int).SetUint64(unsignedTxSize)})
+}
+
+/* --------------------------- Decode (return data) -------------------------- */
+
+// DecodeWord decodes the single uint/bool word every getter returns.
+func DecodeWord(outputHex string) (*big.Int, error) {
+	b, err := utils.HexToBytes(outputHex)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 32 {
+		return nil, errors.New("unexpected return data length")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
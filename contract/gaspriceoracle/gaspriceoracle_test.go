@@ -0,0 +1,94 @@
+package gaspriceoracle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+func TestFlzCompressLen(t *testing.T) {
+	// Vectors from op-geth's TestFlzCompressLen.
+	require.Equal(t, uint32(0), FlzCompressLen([]byte{}))
+	require.Equal(t, uint32(21), FlzCompressLen(bytes.Repeat([]byte{1}, 1000)))
+	require.Equal(t, uint32(21), FlzCompressLen(make([]byte, 1000)))
+}
+
+func TestL1Fee(t *testing.T) {
+	tx := []byte{0, 1, 1} // 36 calldata gas
+
+	bedrock := &Params{L1BaseFee: big.NewInt(1e9), Overhead: big.NewInt(188), Scalar: big.NewInt(684000), Decimals: big.NewInt(6)}
+	require.Equal(t, int64(153216000000), bedrock.L1Fee(tx).Int64())
+	require.Equal(t, uint64(224), bedrock.L1GasUsed(tx))
+
+	ecotone := &Params{L1BaseFee: big.NewInt(1e9), BlobBaseFee: big.NewInt(1), BaseFeeScalar: 1368, BlobBaseFeeScalar: 810949, IsEcotone: true}
+	require.Equal(t, int64(49248001), ecotone.L1Fee(tx).Int64())
+
+	// Small transactions are billed at the 100-byte minimum.
+	fjord := *ecotone
+	fjord.IsFjord = true
+	require.Equal(t, int64(2188800081), fjord.L1Fee(make([]byte, 100)).Int64())
+	require.Equal(t, uint64(1600), fjord.L1GasUsed(tx))
+}
+
+func TestReadParams(t *testing.T) {
+	r, err := New()
+	require.NoError(t, err)
+
+	// An Ecotone oracle: overhead/scalar are deprecated and isFjord is missing.
+	results := map[string]string{}
+	for name, v := range map[string]int64{"l1BaseFee": 1e9, "blobBaseFee": 1, "baseFeeScalar": 1368, "blobBaseFeeScalar": 810949, "decimals": 6, "isEcotone": 1} {
+		sel, err := r.PackNoArgs(name)
+		require.NoError(t, err)
+		results[hex.EncodeToString(sel)] = fmt.Sprintf("0x%064x", v)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		var reqs []codec.Request
+		if json.Unmarshal(body, &reqs) != nil {
+			var q codec.Request
+			require.NoError(t, json.Unmarshal(body, &q))
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":"0xa"}`, q.ID) // eth_chainId
+			return
+		}
+		res := make([]codec.Response, len(reqs))
+		for i, q := range reqs {
+			var params []json.RawMessage
+			require.NoError(t, json.Unmarshal(q.Params, &params))
+			var msg struct {
+				Data string `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(params[0], &msg))
+			res[i].ID = q.ID
+			if v, ok := results[strings.TrimPrefix(msg.Data, "0x")]; ok {
+				res[i].Result = json.RawMessage(`"` + v + `"`)
+			} else {
+				res[i].Error = &codec.ErrorObject{Code: 3, Message: "execution reverted"}
+			}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient(srv.URL)
+	require.NoError(t, err)
+	p, err := r.ReadParams(t.Context(), c)
+	require.NoError(t, err)
+	require.True(t, p.IsEcotone)
+	require.False(t, p.IsFjord)
+	require.Nil(t, p.Overhead)
+	require.Equal(t, uint64(1368), p.BaseFeeScalar)
+	require.Equal(t, int64(49248001), p.L1Fee([]byte{0, 1, 1}).Int64())
+}
@@ -0,0 +1,103 @@
+package gaspriceoracle
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/umbracle/ethgo"
+)
+
+/* ---------------------------- Reads (eth_call) ----------------------------- */
+
+// ReadParams reads the oracle's fee parameters in one batch. Getters that
+// the deployed oracle does not have yet (or no longer has) revert and are
+// left zero.
+func (r *Runtime) ReadParams(ctx context.Context, c *client.Client) (*Params, error) {
+	names := []string{"l1BaseFee", "blobBaseFee", "baseFeeScalar", "blobBaseFeeScalar", "overhead", "scalar", "decimals", "isEcotone", "isFjord"}
+	b := c.NewBatch()
+	calls := make(map[string]*client.BatchCall[string], len(names))
+	for _, name := range names {
+		data, err := r.PackNoArgs(name)
+		if err != nil {
+			return nil, err
+		}
+		calls[name] = b.Call(&client.CallMsg{To: &Address, Data: data}, ethgo.Latest)
+	}
+	if err := b.SendContext(ctx); err != nil {
+		return nil, err
+	}
+
+	words := make(map[string]*big.Int, len(names))
+	for _, name := range names {
+		out, err := calls[name].Result()
+		if errors.Is(err, client.ErrExecutionReverted) || (err == nil && (out == "0x" || out == "")) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if words[name], err = DecodeWord(out); err != nil {
+			return nil, err
+		}
+	}
+	if words["l1BaseFee"] == nil {
+		return nil, errors.New("GasPriceOracle not found (not an OP Stack chain?)")
+	}
+
+	p := &Params{
+		L1BaseFee:   words["l1BaseFee"],
+		BlobBaseFee: words["blobBaseFee"],
+		Overhead:    words["overhead"],
+		Scalar:      words["scalar"],
+		Decimals:    words["decimals"],
+		IsEcotone:   words["isEcotone"] != nil && words["isEcotone"].Sign() != 0,
+		IsFjord:     words["isFjord"] != nil && words["isFjord"].Sign() != 0,
+	}
+	if v := words["baseFeeScalar"]; v != nil {
+		p.BaseFeeScalar = v.Uint64()
+	}
+	if v := words["blobBaseFeeScalar"]; v != nil {
+		p.BlobBaseFeeScalar = v.Uint64()
+	}
+	return p, nil
+}
+
+// EstimateL1Fee reads the current oracle parameters and returns the L1 data
+// fee of a signed transaction.
+func (r *Runtime) EstimateL1Fee(ctx context.Context, c *client.Client, signedTx []byte) (*big.Int, error) {
+	p, err := r.ReadParams(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return p.L1Fee(signedTx), nil
+}
+
+// GetL1Fee asks the oracle itself. data is the unsigned RLP transaction; the
+// oracle pads it for the signature. Useful to cross-check EstimateL1Fee.
+func (r *Runtime) GetL1Fee(ctx context.Context, c *client.Client, data []byte) (*big.Int, error) {
+	in, err := r.PackGetL1Fee(data)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.CallContext(ctx, &client.CallMsg{To: &Address, Data: in}, ethgo.Latest)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeWord(out)
+}
+
+// GetL1FeeUpperBound returns the oracle's worst-case L1 fee for an unsigned
+// transaction of the given size (Fjord and later).
+func (r *Runtime) GetL1FeeUpperBound(ctx context.Context, c *client.Client, unsignedTxSize uint64) (*big.Int, error) {
+	in, err := r.PackGetL1FeeUpperBound(unsignedTxSize)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.CallContext(ctx, &client.CallMsg{To: &Address, Data: in}, ethgo.Latest)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeWord(out)
+}
@@ -0,0 +1,287 @@
+package permit2
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// Address is the canonical Permit2 deployment (same on every supported chain).
+var Address = ethgo.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+
+const (
+	tupleTokenPermissions = `{"name":"permitted","type":"tuple","components":[{"name":"token","type":"address"},{"name":"amount","type":"uint256"}]}`
+	tupleTokenPermsArray  = `{"name":"permitted","type":"tuple[]","components":[{"name":"token","type":"address"},{"name":"amount","type":"uint256"}]}`
+	tuplePermitDetails    = `[{"name":"token","type":"address"},{"name":"amount","type":"uint160"},{"name":"expiration","type":"uint48"},{"name":"nonce","type":"uint48"}]`
+	tupleTransferDetails  = `[{"name":"to","type":"address"},{"name":"requestedAmount","type":"uint256"}]`
+)
+
+const RawABI = `[
+  {"name":"DOMAIN_SEPARATOR","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"bytes32"}]},
+  {"name":"allowance","type":"function","stateMutability":"view","inputs":[{"name":"user","type":"address"},{"name":"token","type":"address"},{"name":"spender","type":"address"}],"outputs":[{"name":"amount","type":"uint160"},{"name":"expiration","type":"uint48"},{"name":"nonce","type":"uint48"}]},
+  {"name":"nonceBitmap","type":"function","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"wordPos","type":"uint256"}],"outputs":[{"name":"bitmap","type":"uint256"}]},
+  {"name":"approve","type":"function","stateMutability":"nonpayable","inputs":[{"name":"token","type":"address"},{"name":"spender","type":"address"},{"name":"amount","type":"uint160"},{"name":"expiration","type":"uint48"}],"outputs":[]},
+  {"name":"invalidateNonces","type":"function","stateMutability":"nonpayable","inputs":[{"name":"token","type":"address"},{"name":"spender","type":"address"},{"name":"newNonce","type":"uint48"}],"outputs":[]},
+  {"name":"invalidateUnorderedNonces","type":"function","stateMutability":"nonpayable","inputs":[{"name":"wordPos","type":"uint256"},{"name":"mask","type":"uint256"}],"outputs":[]},
+  {"name":"lockdown","type":"function","stateMutability":"nonpayable","inputs":[{"name":"approvals","type":"tuple[]","components":[{"name":"token","type":"address"},{"name":"spender","type":"address"}]}],"outputs":[]},
+  {"name":"transferFrom","type":"function","stateMutability":"nonpayable","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"amount","type":"uint160"},{"name":"token","type":"address"}],"outputs":[]},
+  {"name":"permit","type":"function","stateMutability":"nonpayable","inputs":[
+    {"name":"owner","type":"address"},
+    {"name":"permitSingle","type":"tuple","components":[{"name":"details","type":"tuple","components":` + tuplePermitDetails + `},{"name":"spender","type":"address"},{"name":"sigDeadline","type":"uint256"}]},
+    {"name":"signature","type":"bytes"}],"outputs":[]},
+  {"name":"permit","type":"function","stateMutability":"nonpayable","inputs":[
+    {"name":"owner","type":"address"},
+    {"name":"permitBatch","type":"tuple","components":[{"name":"details","type":"tuple[]","components":` + tuplePermitDetails + `},{"name":"spender","type":"address"},{"name":"sigDeadline","type":"uint256"}]},
+    {"name":"signature","type":"bytes"}],"outputs":[]},
+  {"name":"permitTransferFrom","type":"function","stateMutability":"nonpayable","inputs":[
+    {"name":"permit","type":"tuple","components":[` + tupleTokenPermissions + `,{"name":"nonce","type":"uint256"},{"name":"deadline","type":"uint256"}]},
+    {"name":"transferDetails","type":"tuple","components":` + tupleTransferDetails + `},
+    {"name":"owner","type":"address"},
+    {"name":"signature","type":"bytes"}],"outputs":[]},
+  {"name":"permitTransferFrom","type":"function","stateMutability":"nonpayable","inputs":[
+    {"name":"permit","type":"tuple","components":[` + tupleTokenPermsArray + `,{"name":"nonce","type":"uint256"},{"name":"deadline","type":"uint256"}]},
+    {"name":"transferDetails","type":"tuple[]","components":` + tupleTransferDetails + `},
+    {"name":"owner","type":"address"},
+    {"name":"signature","type":"bytes"}],"outputs":[]},
+  {"name":"permitWitnessTransferFrom","type":"function","stateMutability":"nonpayable","inputs":[
+    {"name":"permit","type":"tuple","components":[` + tupleTokenPermissions + `,{"name":"nonce","type":"uint256"},{"name":"deadline","type":"uint256"}]},
+    {"name":"transferDetails","type":"tuple","components":` + tupleTransferDetails + `},
+    {"name":"owner","type":"address"},
+    {"name":"witness","type":"bytes32"},
+    {"name":"witnessTypeString","type":"string"},
+    {"name":"signature","type":"bytes"}],"outputs":[]},
+  {"name":"permitWitnessTransferFrom","type":"function","stateMutability":"nonpayable","inputs":[
+    {"name":"permit","type":"tuple","components":[` + tupleTokenPermsArray + `,{"name":"nonce","type":"uint256"},{"name":"deadline","type":"uint256"}]},
+    {"name":"transferDetails","type":"tuple[]","components":` + tupleTransferDetails + `},
+    {"name":"owner","type":"address"},
+    {"name":"witness","type":"bytes32"},
+    {"name":"witnessTypeString","type":"string"},
+    {"name":"signature","type":"bytes"}],"outputs":[]}
+]`
+
+const (
+	permitSingleSig        = "permit(address,((address,uint160,uint48,uint48),address,uint256),bytes)"
+	permitBatchSig         = "permit(address,((address,uint160,uint48,uint48)[],address,uint256),bytes)"
+	permitTransferSig      = "permitTransferFrom(((address,uint256),uint256,uint256),(address,uint256),address,bytes)"
+	permitBatchTransferSig = "permitTransferFrom(((address,uint256)[],uint256,uint256),(address,uint256)[],address,bytes)"
+	permitWitnessSig       = "permitWitnessTransferFrom(((address,uint256),uint256,uint256),(address,uint256),address,bytes32,string,bytes)"
+	permitBatchWitnessSig  = "permitWitnessTransferFrom(((address,uint256)[],uint256,uint256),(address,uint256)[],address,bytes32,string,bytes)"
+)
+
+type Runtime struct {
+	a *abi.ABI
+}
+
+func New() (*Runtime, error) {
+	a, err := abi.NewABI(RawABI)
+	if err != nil {
+		return nil, err
+	}
+	return &Runtime{a: a}, nil
+}
+
+func (r *Runtime) method(name string) (*abi.Method, error) {
+	m := r.a.Methods[name]
+	if m == nil {
+		m = r.a.MethodsBySignature[name]
+	}
+	if m == nil {
+		return nil, errors.New("method not found: " + name)
+	}
+	return m, nil
+}
+
+/* ----------------------------- Pack (tx data) ------------------------------ */
+
+// PackPermitSingle builds calldata for AllowanceTransfer.permit(owner, PermitSingle, signature).
+func (r *Runtime) PackPermitSingle(owner ethgo.Address, p *PermitSingle, signature []byte) ([]byte, error) {
+	m, err := r.method(permitSingleSig)
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]any{owner, map[string]any{
+		"details":     p.Details.abiValue(),
+		"spender":     p.Spender,
+		"sigDeadline": p.SigDeadline,
+	}, signature})
+}
+
+// PackPermitBatch builds calldata for AllowanceTransfer.permit(owner, PermitBatch, signature).
+func (r *Runtime) PackPermitBatch(owner ethgo.Address, p *PermitBatch, signature []byte) ([]byte, error) {
+	m, err := r.method(permitBatchSig)
+	if err != nil {
+		return nil, err
+	}
+	details := make([]map[string]any, len(p.Details))
+	for i := range p.Details {
+		details[i] = p.Details[i].abiValue()
+	}
+	return m.Encode([]any{owner, map[string]any{
+		"details":     details,
+		"spender":     p.Spender,
+		"sigDeadline": p.SigDeadline,
+	}, signature})
+}
+
+// PackTransferFrom builds calldata for AllowanceTransfer.transferFrom(from, to, amount, token).
+func (r *Runtime) PackTransferFrom(from, to ethgo.Address, amount *big.Int, token ethgo.Address) ([]byte, error) {
+	m, err := r.method("transferFrom")
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]any{from, to, amount, token})
+}
+
+// PackApprove builds calldata for AllowanceTransfer.approve(token, spender, amount, expiration).
+func (r *Runtime) PackApprove(token, spender ethgo.Address, amount *big.Int, expiration uint64) ([]byte, error) {
+	m, err := r.method("approve")
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]any{token, spender, amount, utils.U64ToBig(expiration)})
+}
+
+// PackInvalidateNonces builds calldata for invalidateNonces(token, spender, newNonce).
+func (r *Runtime) PackInvalidateNonces(token, spender ethgo.Address, newNonce uint64) ([]byte, error) {
+	m, err := r.method("invalidateNonces")
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]any{token, spender, utils.U64ToBig(newNonce)})
+}
+
+// PackInvalidateUnorderedNonces builds calldata for invalidateUnorderedNonces(wordPos, mask).
+func (r *Runtime) PackInvalidateUnorderedNonces(wordPos, mask *big.Int) ([]byte, error) {
+	m, err := r.method("invalidateUnorderedNonces")
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]any{wordPos, mask})
+}
+
+// PackLockdown builds calldata for lockdown(approvals) revoking each token/spender pair.
+func (r *Runtime) PackLockdown(pairs []TokenSpenderPair) ([]byte, error) {
+	m, err := r.method("lockdown")
+	if err != nil {
+		return nil, err
+	}
+	arg := make([]map[string]any, len(pairs))
+	for i, p := range pairs {
+		arg[i] = map[string]any{"token": p.Token, "spender": p.Spender}
+	}
+	return m.Encode([]any{arg})
+}
+
+// PackPermitTransferFrom builds calldata for SignatureTransfer.permitTransferFrom (single).
+func (r *Runtime) PackPermitTransferFrom(p *PermitTransferFrom, details SignatureTransferDetails, owner ethgo.Address, signature []byte) ([]byte, error) {
+	m, err := r.method(permitTransferSig)
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]any{p.abiValue(), details.abiValue(), owner, signature})
+}
+
+// PackPermitBatchTransferFrom builds calldata for SignatureTransfer.permitTransferFrom (batch).
+func (r *Runtime) PackPermitBatchTransferFrom(p *PermitBatchTransferFrom, details []SignatureTransferDetails, owner ethgo.Address, signature []byte) ([]byte, error) {
+	m, err := r.method(permitBatchTransferSig)
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]any{p.abiValue(), transferDetailsABI(details), owner, signature})
+}
+
+// PackPermitWitnessTransferFrom builds calldata for SignatureTransfer.permitWitnessTransferFrom (single).
+func (r *Runtime) PackPermitWitnessTransferFrom(p *PermitTransferFrom, details SignatureTransferDetails, owner ethgo.Address, w *Witness, signature []byte) ([]byte, error) {
+	m, err := r.method(permitWitnessSig)
+	if err != nil {
+		return nil, err
+	}
+	hash, typeString, err := w.onchainArgs(false)
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]any{p.abiValue(), details.abiValue(), owner, hash, typeString, signature})
+}
+
+// PackPermitBatchWitnessTransferFrom builds calldata for SignatureTransfer.permitWitnessTransferFrom (batch).
+func (r *Runtime) PackPermitBatchWitnessTransferFrom(p *PermitBatchTransferFrom, details []SignatureTransferDetails, owner ethgo.Address, w *Witness, signature []byte) ([]byte, error) {
+	m, err := r.method(permitBatchWitnessSig)
+	if err != nil {
+		return nil, err
+	}
+	hash, typeString, err := w.onchainArgs(true)
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]any{p.abiValue(), transferDetailsABI(details), owner, hash, typeString, signature})
+}
+
+func (r *Runtime) PackAllowance(user, token, spender ethgo.Address) ([]byte, error) {
+	m, err := r.method("allowance")
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]any{user, token, spender})
+}
+
+func (r *Runtime) PackNonceBitmap(owner ethgo.Address, wordPos *big.Int) ([]byte, error) {
+	m, err := r.method("nonceBitmap")
+	if err != nil {
+		return nil, err
+	}
+	return m.Encode([]any{owner, wordPos})
+}
+
+/* ---------------------------- Decode (call outs) --------------------------- */
+
+// Allowance is the AllowanceTransfer state for a (user, token, spender) triple.
+type Allowance struct {
+	Amount     *big.Int
+	Expiration uint64
+	Nonce      uint64
+}
+
+func (r *Runtime) DecodeAllowance(outputHex string) (*Allowance, error) {
+	b, err := utils.HexToBytes(outputHex)
+	if err != nil {
+		return nil, err
+	}
+	m, err := r.method("allowance")
+	if err != nil {
+		return nil, err
+	}
+	out, err := m.Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	amount, ok1 := out["amount"].(*big.Int)
+	expiration, ok2 := out["expiration"].(*big.Int)
+	nonce, ok3 := out["nonce"].(*big.Int)
+	if !ok1 || !ok2 || !ok3 {
+		return nil, errors.New("unexpected allowance output types")
+	}
+	return &Allowance{Amount: amount, Expiration: expiration.Uint64(), Nonce: nonce.Uint64()}, nil
+}
+
+func (r *Runtime) DecodeNonceBitmap(outputHex string) (*big.Int, error) {
+	b, err := utils.HexToBytes(outputHex)
+	if err != nil {
+		return nil, err
+	}
+	m, err := r.method("nonceBitmap")
+	if err != nil {
+		return nil, err
+	}
+	out, err := m.Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	bitmap, ok := out["bitmap"].(*big.Int)
+	if !ok {
+		return nil, errors.New("unexpected output type (want *big.Int)")
+	}
+	return bitmap, nil
+}
@@ -0,0 +1,122 @@
+package permit2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/gosunuts/ethtxbuilder/transaction"
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+)
+
+func TestTypeStrings(t *testing.T) {
+	single := (&PermitSingle{}).TypedData(Domain(big.NewInt(1)))
+	require.Equal(t,
+		"PermitSingle(PermitDetails details,address spender,uint256 sigDeadline)PermitDetails(address token,uint160 amount,uint48 expiration,uint48 nonce)",
+		string(single.EncodeType("PermitSingle")))
+
+	transfer, err := (&PermitTransferFrom{}).TypedData(Domain(big.NewInt(1)), nil)
+	require.NoError(t, err)
+	require.Equal(t,
+		"PermitTransferFrom(TokenPermissions permitted,address spender,uint256 nonce,uint256 deadline)TokenPermissions(address token,uint256 amount)",
+		string(transfer.EncodeType("PermitTransferFrom")))
+}
+
+func TestWitness(t *testing.T) {
+	w := &Witness{
+		TypeName: "ExampleWitness",
+		Types: transaction.Types{
+			"ExampleWitness": {{Name: "user", Type: "address"}},
+		},
+		Value: map[string]any{"user": "0x0000000000000000000000000000000000000001"},
+	}
+
+	ts, err := w.TypeString(false)
+	require.NoError(t, err)
+	require.Equal(t, "ExampleWitness witness)ExampleWitness(address user)TokenPermissions(address token,uint256 amount)", ts)
+
+	p := &PermitTransferFrom{
+		Permitted: TokenPermissions{Token: ethgo.HexToAddress("0x02"), Amount: big.NewInt(10)},
+		Spender:   ethgo.HexToAddress("0x03"),
+		Nonce:     big.NewInt(0),
+		Deadline:  big.NewInt(1e10),
+	}
+	sign := utils.NewRawPrivateSigner("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	sig, err := p.Sign(Domain(big.NewInt(1)), w, sign)
+	require.NoError(t, err)
+
+	td, err := p.TypedData(Domain(big.NewInt(1)), w)
+	require.NoError(t, err)
+	digest, err := td.HashTypedData()
+	require.NoError(t, err)
+	addr, err := utils.RecoverAddress(digest, sig)
+	require.NoError(t, err)
+	require.Equal(t, "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23", addr)
+
+	r, err := New()
+	require.NoError(t, err)
+	data, err := r.PackPermitWitnessTransferFrom(p, SignatureTransferDetails{To: p.Spender, RequestedAmount: big.NewInt(10)}, ethgo.HexToAddress(addr), w, sig)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x13, 0x7c, 0x29, 0xfe}, data[:4])
+}
+
+// Permit2 constants: DOMAIN_SEPARATOR() of the mainnet deployment,
+// _TOKEN_PERMISSIONS_TYPEHASH, and the witness type string of Permit2's own
+// SignatureTransfer tests.
+const (
+	mainnetSeparator   = "866a5aba21966af95d6c7ab78eb2b2fc913915c28be3b9aa07cc04ff903e3f28"
+	tokenPermissionsTH = "618358ac3db8dc274f0cd8829da7e234bd48cd73c4a740aede1adec9846d06a1"
+	mockWitnessType    = "MockWitness witness)MockWitness(uint256 value,address person,bool test)TokenPermissions(address token,uint256 amount)"
+)
+
+// TestWitnessDigest checks a PermitWitnessTransferFrom digest against the
+// hash built the way PermitHash.hashWithWitness and EIP712 do on-chain.
+func TestWitnessDigest(t *testing.T) {
+	word := func(b []byte) []byte { return utils.LeftPad32(b) }
+	person := ethgo.HexToAddress("0x0000000000000000000000000000000000000005")
+	w := &Witness{
+		TypeName: "MockWitness",
+		Types: transaction.Types{
+			"MockWitness": {{Name: "value", Type: "uint256"}, {Name: "person", Type: "address"}, {Name: "test", Type: "bool"}},
+		},
+		Value: map[string]any{"value": big.NewInt(10000000), "person": person.String(), "test": true},
+	}
+	p := &PermitTransferFrom{
+		Permitted: TokenPermissions{Token: ethgo.HexToAddress("0x0000000000000000000000000000000000000002"), Amount: big.NewInt(1e18)},
+		Spender:   ethgo.HexToAddress("0x0000000000000000000000000000000000000003"),
+		Nonce:     big.NewInt(0),
+		Deadline:  big.NewInt(1e10),
+	}
+
+	ts, err := w.TypeString(false)
+	require.NoError(t, err)
+	require.Equal(t, mockWitnessType, ts)
+
+	td, err := p.TypedData(Domain(big.NewInt(1)), w)
+	require.NoError(t, err)
+	ds, err := td.DomainSeparator()
+	require.NoError(t, err)
+	require.Equal(t, mainnetSeparator, hex.EncodeToString(ds))
+
+	witness := utils.Keccak(bytes.Join([][]byte{
+		utils.Keccak([]byte("MockWitness(uint256 value,address person,bool test)")),
+		word(big.NewInt(10000000).Bytes()), word(person[:]), word([]byte{1}),
+	}, nil))
+	tp, _ := hex.DecodeString(tokenPermissionsTH)
+	permitted := utils.Keccak(bytes.Join([][]byte{tp, word(p.Permitted.Token[:]), word(p.Permitted.Amount.Bytes())}, nil))
+	typeHash := utils.Keccak([]byte("PermitWitnessTransferFrom(TokenPermissions permitted,address spender,uint256 nonce,uint256 deadline," + mockWitnessType))
+	structHash := utils.Keccak(bytes.Join([][]byte{
+		typeHash, permitted, word(p.Spender[:]), word(p.Nonce.Bytes()), word(p.Deadline.Bytes()), witness,
+	}, nil))
+	want := utils.Keccak(bytes.Join([][]byte{{0x19, 0x01}, ds, structHash}, nil))
+
+	got, err := td.HashTypedData()
+	require.NoError(t, err)
+	require.Equal(t, hex.EncodeToString(want), hex.EncodeToString(got))
+	wh, err := w.Hash()
+	require.NoError(t, err)
+	require.Equal(t, witness, wh[:])
+}
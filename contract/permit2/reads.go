@@ -0,0 +1,53 @@
+package permit2
+
+import (
+	"math/big"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/umbracle/ethgo"
+)
+
+/* ---------------------------- Reads (eth_call) ----------------------------- */
+
+// Allowance reads the AllowanceTransfer state for (user, token, spender).
+func (r *Runtime) Allowance(c *client.Client, user, token, spender ethgo.Address) (*Allowance, error) {
+	data, err := r.PackAllowance(user, token, spender)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.Call(&client.CallMsg{To: &Address, Data: data}, ethgo.Latest)
+	if err != nil {
+		return nil, err
+	}
+	return r.DecodeAllowance(out)
+}
+
+// NonceBitmap reads the unordered-nonce bitmap word at wordPos for owner.
+func (r *Runtime) NonceBitmap(c *client.Client, owner ethgo.Address, wordPos *big.Int) (*big.Int, error) {
+	data, err := r.PackNonceBitmap(owner, wordPos)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.Call(&client.CallMsg{To: &Address, Data: data}, ethgo.Latest)
+	if err != nil {
+		return nil, err
+	}
+	return r.DecodeNonceBitmap(out)
+}
+
+// IsNonceUsed reports whether a SignatureTransfer nonce has been used or invalidated.
+func (r *Runtime) IsNonceUsed(c *client.Client, owner ethgo.Address, nonce *big.Int) (bool, error) {
+	wordPos, bitPos := NoncePosition(nonce)
+	bitmap, err := r.NonceBitmap(c, owner, wordPos)
+	if err != nil {
+		return false, err
+	}
+	return bitmap.Bit(int(bitPos)) == 1, nil
+}
+
+// NoncePosition splits an unordered nonce into its bitmap word and bit index.
+func NoncePosition(nonce *big.Int) (wordPos *big.Int, bitPos uint8) {
+	wordPos = new(big.Int).Rsh(nonce, 8)
+	bitPos = uint8(new(big.Int).And(nonce, big.NewInt(0xff)).Uint64())
+	return wordPos, bitPos
+}
@@ -0,0 +1,378 @@
+package permit2
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/gosunuts/ethtxbuilder/transaction"
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+)
+
+/* ----------------------------- Structures ---------------------------------- */
+
+// PermitDetails is the per-token part of an AllowanceTransfer permit.
+type PermitDetails struct {
+	Token      ethgo.Address
+	Amount     *big.Int // uint160
+	Expiration uint64   // uint48
+	Nonce      uint64   // uint48
+}
+
+// PermitSingle grants spender an allowance on one token.
+type PermitSingle struct {
+	Details     PermitDetails
+	Spender     ethgo.Address
+	SigDeadline *big.Int
+}
+
+// PermitBatch grants spender allowances on several tokens.
+type PermitBatch struct {
+	Details     []PermitDetails
+	Spender     ethgo.Address
+	SigDeadline *big.Int
+}
+
+// TokenPermissions is the token/amount pair of a SignatureTransfer permit.
+type TokenPermissions struct {
+	Token  ethgo.Address
+	Amount *big.Int
+}
+
+// PermitTransferFrom is a one-time SignatureTransfer permit.
+// Spender is signed over but not part of the calldata (it is msg.sender).
+type PermitTransferFrom struct {
+	Permitted TokenPermissions
+	Spender   ethgo.Address
+	Nonce     *big.Int // unordered nonce (bitmap)
+	Deadline  *big.Int
+}
+
+// PermitBatchTransferFrom is a one-time SignatureTransfer permit over several tokens.
+type PermitBatchTransferFrom struct {
+	Permitted []TokenPermissions
+	Spender   ethgo.Address
+	Nonce     *big.Int
+	Deadline  *big.Int
+}
+
+// SignatureTransferDetails is the recipient part supplied by the spender.
+type SignatureTransferDetails struct {
+	To              ethgo.Address
+	RequestedAmount *big.Int
+}
+
+// TokenSpenderPair identifies an allowance to revoke with lockdown.
+type TokenSpenderPair struct {
+	Token   ethgo.Address
+	Spender ethgo.Address
+}
+
+// Witness is extra typed data bound to a SignatureTransfer permit.
+// Types must define TypeName and every struct it references.
+type Witness struct {
+	TypeName string
+	Types    transaction.Types
+	Value    map[string]any
+}
+
+/* ----------------------------- Type strings -------------------------------- */
+
+const witnessField = "witness"
+
+var (
+	tokenPermissionsFields = []transaction.Field{
+		{Name: "token", Type: "address"},
+		{Name: "amount", Type: "uint256"},
+	}
+	permitDetailsFields = []transaction.Field{
+		{Name: "token", Type: "address"},
+		{Name: "amount", Type: "uint160"},
+		{Name: "expiration", Type: "uint48"},
+		{Name: "nonce", Type: "uint48"},
+	}
+	permitTransferFromFields = []transaction.Field{
+		{Name: "permitted", Type: "TokenPermissions"},
+		{Name: "spender", Type: "address"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "deadline", Type: "uint256"},
+	}
+	permitBatchTransferFromFields = []transaction.Field{
+		{Name: "permitted", Type: "TokenPermissions[]"},
+		{Name: "spender", Type: "address"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "deadline", Type: "uint256"},
+	}
+)
+
+// Domain returns the Permit2 EIP-712 domain for the canonical deployment.
+// Permit2 has no version field.
+func Domain(chainID *big.Int) transaction.Domain {
+	return transaction.Domain{
+		Name:              "Permit2",
+		ChainID:           chainID,
+		VerifyingContract: Address.String(),
+	}
+}
+
+/* ----------------------------- Typed data ---------------------------------- */
+
+// TypedData builds the PermitSingle typed data.
+func (p *PermitSingle) TypedData(domain transaction.Domain) *transaction.TypedData {
+	return &transaction.TypedData{
+		Types: transaction.Types{
+			"PermitSingle": {
+				{Name: "details", Type: "PermitDetails"},
+				{Name: "spender", Type: "address"},
+				{Name: "sigDeadline", Type: "uint256"},
+			},
+			"PermitDetails": permitDetailsFields,
+		},
+		PrimaryType: "PermitSingle",
+		Domain:      domain,
+		Message: map[string]any{
+			"details":     p.Details.message(),
+			"spender":     p.Spender.String(),
+			"sigDeadline": p.SigDeadline,
+		},
+	}
+}
+
+// TypedData builds the PermitBatch typed data.
+func (p *PermitBatch) TypedData(domain transaction.Domain) *transaction.TypedData {
+	details := make([]map[string]any, len(p.Details))
+	for i := range p.Details {
+		details[i] = p.Details[i].message()
+	}
+	return &transaction.TypedData{
+		Types: transaction.Types{
+			"PermitBatch": {
+				{Name: "details", Type: "PermitDetails[]"},
+				{Name: "spender", Type: "address"},
+				{Name: "sigDeadline", Type: "uint256"},
+			},
+			"PermitDetails": permitDetailsFields,
+		},
+		PrimaryType: "PermitBatch",
+		Domain:      domain,
+		Message: map[string]any{
+			"details":     details,
+			"spender":     p.Spender.String(),
+			"sigDeadline": p.SigDeadline,
+		},
+	}
+}
+
+// TypedData builds the PermitTransferFrom typed data, or
+// PermitWitnessTransferFrom when w is non-nil.
+func (p *PermitTransferFrom) TypedData(domain transaction.Domain, w *Witness) (*transaction.TypedData, error) {
+	msg := map[string]any{
+		"permitted": p.Permitted.message(),
+		"spender":   p.Spender.String(),
+		"nonce":     p.Nonce,
+		"deadline":  p.Deadline,
+	}
+	return buildTransferTypedData(domain, false, msg, w)
+}
+
+// TypedData builds the PermitBatchTransferFrom typed data, or
+// PermitBatchWitnessTransferFrom when w is non-nil.
+func (p *PermitBatchTransferFrom) TypedData(domain transaction.Domain, w *Witness) (*transaction.TypedData, error) {
+	permitted := make([]map[string]any, len(p.Permitted))
+	for i := range p.Permitted {
+		permitted[i] = p.Permitted[i].message()
+	}
+	msg := map[string]any{
+		"permitted": permitted,
+		"spender":   p.Spender.String(),
+		"nonce":     p.Nonce,
+		"deadline":  p.Deadline,
+	}
+	return buildTransferTypedData(domain, true, msg, w)
+}
+
+func buildTransferTypedData(domain transaction.Domain, batch bool, msg map[string]any, w *Witness) (*transaction.TypedData, error) {
+	primary, types, err := transferTypes(batch, w)
+	if err != nil {
+		return nil, err
+	}
+	if w != nil {
+		msg[witnessField] = w.Value
+	}
+	return &transaction.TypedData{
+		Types:       types,
+		PrimaryType: primary,
+		Domain:      domain,
+		Message:     msg,
+	}, nil
+}
+
+func transferTypes(batch bool, w *Witness) (string, transaction.Types, error) {
+	primary, fields := "PermitTransferFrom", permitTransferFromFields
+	if batch {
+		primary, fields = "PermitBatchTransferFrom", permitBatchTransferFromFields
+	}
+	types := transaction.Types{"TokenPermissions": tokenPermissionsFields}
+	if w == nil {
+		types[primary] = fields
+		return primary, types, nil
+	}
+
+	if w.TypeName == "" || w.Types[w.TypeName] == nil {
+		return "", nil, fmt.Errorf("permit2: witness type %q not defined", w.TypeName)
+	}
+	if batch {
+		primary = "PermitBatchWitnessTransferFrom"
+	} else {
+		primary = "PermitWitnessTransferFrom"
+	}
+	for name, fs := range w.Types {
+		if _, clash := types[name]; clash || name == primary {
+			return "", nil, fmt.Errorf("permit2: witness type %q clashes with a Permit2 type", name)
+		}
+		types[name] = fs
+	}
+	types[primary] = append(append([]transaction.Field{}, fields...), transaction.Field{Name: witnessField, Type: w.TypeName})
+	return primary, types, nil
+}
+
+// TypeString returns the witnessTypeString argument expected by
+// permitWitnessTransferFrom: the full EIP-712 type string of the witness
+// primary type without its fixed "PermitWitnessTransferFrom(...," stub.
+func (w *Witness) TypeString(batch bool) (string, error) {
+	primary, types, err := transferTypes(batch, w)
+	if err != nil {
+		return "", err
+	}
+	td := &transaction.TypedData{Types: types}
+	full := string(td.EncodeType(primary))
+
+	stub := primary + "("
+	for _, f := range types[primary][:len(types[primary])-1] {
+		stub += f.Type + " " + f.Name + ","
+	}
+	rest, ok := strings.CutPrefix(full, stub)
+	if !ok {
+		return "", fmt.Errorf("permit2: unexpected witness type string %q", full)
+	}
+	return rest, nil
+}
+
+// Hash returns hashStruct(witness), the bytes32 witness argument.
+func (w *Witness) Hash() (ethgo.Hash, error) {
+	td := &transaction.TypedData{Types: w.Types}
+	h, err := td.HashStruct(w.TypeName, w.Value)
+	if err != nil {
+		return ethgo.Hash{}, err
+	}
+	return ethgo.BytesToHash(h), nil
+}
+
+func (w *Witness) onchainArgs(batch bool) (ethgo.Hash, string, error) {
+	if w == nil {
+		return ethgo.Hash{}, "", fmt.Errorf("permit2: witness required")
+	}
+	h, err := w.Hash()
+	if err != nil {
+		return ethgo.Hash{}, "", err
+	}
+	ts, err := w.TypeString(batch)
+	if err != nil {
+		return ethgo.Hash{}, "", err
+	}
+	return h, ts, nil
+}
+
+/* ----------------------------- Sign ---------------------------------------- */
+
+// Sign signs the PermitSingle for domain.
+func (p *PermitSingle) Sign(domain transaction.Domain, sign utils.SignFunc) ([]byte, error) {
+	return p.TypedData(domain).Sign(sign)
+}
+
+// Sign signs the PermitBatch for domain.
+func (p *PermitBatch) Sign(domain transaction.Domain, sign utils.SignFunc) ([]byte, error) {
+	return p.TypedData(domain).Sign(sign)
+}
+
+// Sign signs the PermitTransferFrom (with optional witness) for domain.
+func (p *PermitTransferFrom) Sign(domain transaction.Domain, w *Witness, sign utils.SignFunc) ([]byte, error) {
+	td, err := p.TypedData(domain, w)
+	if err != nil {
+		return nil, err
+	}
+	return td.Sign(sign)
+}
+
+// Sign signs the PermitBatchTransferFrom (with optional witness) for domain.
+func (p *PermitBatchTransferFrom) Sign(domain transaction.Domain, w *Witness, sign utils.SignFunc) ([]byte, error) {
+	td, err := p.TypedData(domain, w)
+	if err != nil {
+		return nil, err
+	}
+	return td.Sign(sign)
+}
+
+/* ----------------------------- Encoding helpers ----------------------------- */
+
+func (d *PermitDetails) message() map[string]any {
+	return map[string]any{
+		"token":      d.Token.String(),
+		"amount":     d.Amount,
+		"expiration": d.Expiration,
+		"nonce":      d.Nonce,
+	}
+}
+
+func (d *PermitDetails) abiValue() map[string]any {
+	return map[string]any{
+		"token":      d.Token,
+		"amount":     d.Amount,
+		"expiration": utils.U64ToBig(d.Expiration),
+		"nonce":      utils.U64ToBig(d.Nonce),
+	}
+}
+
+func (t *TokenPermissions) message() map[string]any {
+	return map[string]any{
+		"token":  t.Token.String(),
+		"amount": t.Amount,
+	}
+}
+
+func (t *TokenPermissions) abiValue() map[string]any {
+	return map[string]any{"token": t.Token, "amount": t.Amount}
+}
+
+func (p *PermitTransferFrom) abiValue() map[string]any {
+	return map[string]any{
+		"permitted": p.Permitted.abiValue(),
+		"nonce":     p.Nonce,
+		"deadline":  p.Deadline,
+	}
+}
+
+func (p *PermitBatchTransferFrom) abiValue() map[string]any {
+	permitted := make([]map[string]any, len(p.Permitted))
+	for i := range p.Permitted {
+		permitted[i] = p.Permitted[i].abiValue()
+	}
+	return map[string]any{
+		"permitted": permitted,
+		"nonce":     p.Nonce,
+		"deadline":  p.Deadline,
+	}
+}
+
+func (d SignatureTransferDetails) abiValue() map[string]any {
+	return map[string]any{"to": d.To, "requestedAmount": d.RequestedAmount}
+}
+
+func transferDetailsABI(ds []SignatureTransferDetails) []map[string]any {
+	out := make([]map[string]any, len(ds))
+	for i := range ds {
+		out[i] = ds[i].abiValue()
+	}
+	return out
+}
@@ -4,6 +4,7 @@ go 1.25.0
 
 require (
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.0
+	github.com/gorilla/websocket v1.4.1
 	github.com/stretchr/testify v1.11.1
 	github.com/umbracle/ethgo v0.1.4-0.20220810152743-a7c2d014b964
 	github.com/umbracle/fastrlp v0.1.0
@@ -13,7 +14,6 @@ require (
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/google/gofuzz v1.2.0 // indirect
-	github.com/gorilla/websocket v1.4.1 // indirect
 	github.com/klauspost/compress v1.4.1 // indirect
 	github.com/klauspost/cpuid v1.2.0 // indirect
 	github.com/kr/text v0.2.0 // indirect
@@ -0,0 +1,375 @@
+package siwe
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/umbracle/ethgo"
+)
+
+// Message is an EIP-4361 Sign-In with Ethereum message.
+//
+// Timestamps are kept as RFC 3339 strings so that a parsed message renders
+// back byte-for-byte; use FormatTime to fill them from a time.Time.
+type Message struct {
+	Scheme         string // optional, e.g. "https"
+	Domain         string // RFC 3986 authority
+	Address        string // EIP-55 checksummed
+	Statement      string // optional, single line
+	URI            string
+	Version        string // always "1"
+	ChainID        uint64
+	Nonce          string // >= 8 alphanumeric chars
+	IssuedAt       string
+	ExpirationTime string // optional
+	NotBefore      string // optional
+	RequestID      string // optional
+	// Resources is optional. A non-nil empty slice renders a bare
+	// "Resources:" line, which the ABNF allows and ParseMessage keeps.
+	Resources []string
+}
+
+const (
+	Version = "1"
+
+	headerSuffix = " wants you to sign in with your Ethereum account:"
+
+	tagURI            = "URI: "
+	tagVersion        = "Version: "
+	tagChainID        = "Chain ID: "
+	tagNonce          = "Nonce: "
+	tagIssuedAt       = "Issued At: "
+	tagExpirationTime = "Expiration Time: "
+	tagNotBefore      = "Not Before: "
+	tagRequestID      = "Request ID: "
+	tagResources      = "Resources:"
+)
+
+const nonceAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// GenerateNonce returns a random 17-character alphanumeric nonce.
+func GenerateNonce() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(nonceAlphabet)))
+	for i := 0; i < 17; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(nonceAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// FormatTime formats t as an RFC 3339 UTC timestamp.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+/* ---------------- Build ---------------- */
+
+// String renders the message in the EIP-4361 text format.
+// It does not validate; call Validate first when building by hand.
+func (m *Message) String() string {
+	var sb strings.Builder
+	if m.Scheme != "" {
+		sb.WriteString(m.Scheme)
+		sb.WriteString("://")
+	}
+	sb.WriteString(m.Domain)
+	sb.WriteString(headerSuffix)
+	sb.WriteByte('\n')
+	sb.WriteString(m.Address)
+	sb.WriteString("\n\n")
+	if m.Statement != "" {
+		sb.WriteString(m.Statement)
+		sb.WriteByte('\n')
+	}
+	sb.WriteByte('\n')
+
+	sb.WriteString(tagURI + m.URI + "\n")
+	sb.WriteString(tagVersion + m.Version + "\n")
+	sb.WriteString(tagChainID + strconv.FormatUint(m.ChainID, 10) + "\n")
+	sb.WriteString(tagNonce + m.Nonce + "\n")
+	sb.WriteString(tagIssuedAt + m.IssuedAt)
+	if m.ExpirationTime != "" {
+		sb.WriteString("\n" + tagExpirationTime + m.ExpirationTime)
+	}
+	if m.NotBefore != "" {
+		sb.WriteString("\n" + tagNotBefore + m.NotBefore)
+	}
+	if m.RequestID != "" {
+		sb.WriteString("\n" + tagRequestID + m.RequestID)
+	}
+	if m.Resources != nil {
+		sb.WriteString("\n" + tagResources)
+		for _, r := range m.Resources {
+			sb.WriteString("\n- " + r)
+		}
+	}
+	return sb.String()
+}
+
+// Validate checks every field against the EIP-4361 ABNF.
+func (m *Message) Validate() error {
+	if m.Scheme != "" && !isScheme(m.Scheme) {
+		return fmt.Errorf("siwe: invalid scheme %q", m.Scheme)
+	}
+	if !isAuthority(m.Domain) {
+		return fmt.Errorf("siwe: invalid domain %q", m.Domain)
+	}
+	if !isChecksumAddress(m.Address) {
+		return fmt.Errorf("siwe: address must be EIP-55 checksummed: %q", m.Address)
+	}
+	if strings.ContainsAny(m.Statement, "\r\n") {
+		return errors.New("siwe: statement must be a single line")
+	}
+	if !isURI(m.URI) {
+		return fmt.Errorf("siwe: invalid uri %q", m.URI)
+	}
+	if m.Version != Version {
+		return fmt.Errorf("siwe: unsupported version %q", m.Version)
+	}
+	if !isNonce(m.Nonce) {
+		return fmt.Errorf("siwe: nonce must be at least 8 alphanumeric characters: %q", m.Nonce)
+	}
+	if _, err := parseTime(m.IssuedAt); err != nil {
+		return fmt.Errorf("siwe: issued-at: %w", err)
+	}
+	if m.ExpirationTime != "" {
+		if _, err := parseTime(m.ExpirationTime); err != nil {
+			return fmt.Errorf("siwe: expiration-time: %w", err)
+		}
+	}
+	if m.NotBefore != "" {
+		if _, err := parseTime(m.NotBefore); err != nil {
+			return fmt.Errorf("siwe: not-before: %w", err)
+		}
+	}
+	if strings.ContainsAny(m.RequestID, "\r\n") {
+		return errors.New("siwe: request-id must be a single line")
+	}
+	for _, r := range m.Resources {
+		if !isURI(r) {
+			return fmt.Errorf("siwe: invalid resource %q", r)
+		}
+	}
+	return nil
+}
+
+/* ---------------- Parse ---------------- */
+
+// ParseMessage strictly parses an EIP-4361 message. Unknown lines, missing
+// required fields, out-of-order fields and trailing data are rejected.
+func ParseMessage(s string) (*Message, error) {
+	lines := strings.Split(s, "\n")
+	p := &parser{lines: lines}
+	m := &Message{}
+
+	header, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	authority, ok := strings.CutSuffix(header, headerSuffix)
+	if !ok {
+		return nil, errors.New("siwe: invalid header line")
+	}
+	if scheme, rest, found := strings.Cut(authority, "://"); found {
+		m.Scheme, authority = scheme, rest
+	}
+	m.Domain = authority
+
+	if m.Address, err = p.next(); err != nil {
+		return nil, err
+	}
+	if err := p.expectEmpty(); err != nil {
+		return nil, err
+	}
+
+	// [ statement LF ] LF
+	line, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if line != "" {
+		m.Statement = line
+		if err := p.expectEmpty(); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.URI, err = p.tag(tagURI); err != nil {
+		return nil, err
+	}
+	if m.Version, err = p.tag(tagVersion); err != nil {
+		return nil, err
+	}
+	chainID, err := p.tag(tagChainID)
+	if err != nil {
+		return nil, err
+	}
+	if !isDigits(chainID) {
+		return nil, fmt.Errorf("siwe: invalid chain id %q", chainID)
+	}
+	if m.ChainID, err = strconv.ParseUint(chainID, 10, 64); err != nil {
+		return nil, fmt.Errorf("siwe: invalid chain id %q", chainID)
+	}
+	if m.Nonce, err = p.tag(tagNonce); err != nil {
+		return nil, err
+	}
+	if m.IssuedAt, err = p.tag(tagIssuedAt); err != nil {
+		return nil, err
+	}
+	m.ExpirationTime = p.optionalTag(tagExpirationTime)
+	m.NotBefore = p.optionalTag(tagNotBefore)
+	m.RequestID = p.optionalTag(tagRequestID)
+
+	if p.peek() == tagResources {
+		p.pos++
+		m.Resources = []string{}
+		for p.pos < len(p.lines) {
+			r, ok := strings.CutPrefix(p.lines[p.pos], "- ")
+			if !ok {
+				break
+			}
+			m.Resources = append(m.Resources, r)
+			p.pos++
+		}
+	}
+	if p.pos != len(p.lines) {
+		return nil, fmt.Errorf("siwe: unexpected line %d: %q", p.pos+1, p.lines[p.pos])
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type parser struct {
+	lines []string
+	pos   int
+}
+
+func (p *parser) next() (string, error) {
+	if p.pos >= len(p.lines) {
+		return "", errors.New("siwe: unexpected end of message")
+	}
+	l := p.lines[p.pos]
+	p.pos++
+	return l, nil
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.lines) {
+		return ""
+	}
+	return p.lines[p.pos]
+}
+
+func (p *parser) expectEmpty() error {
+	l, err := p.next()
+	if err != nil {
+		return err
+	}
+	if l != "" {
+		return fmt.Errorf("siwe: expected empty line %d, got %q", p.pos, l)
+	}
+	return nil
+}
+
+func (p *parser) tag(prefix string) (string, error) {
+	l, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	v, ok := strings.CutPrefix(l, prefix)
+	if !ok {
+		return "", fmt.Errorf("siwe: expected %q on line %d", strings.TrimSpace(prefix), p.pos)
+	}
+	return v, nil
+}
+
+func (p *parser) optionalTag(prefix string) string {
+	v, ok := strings.CutPrefix(p.peek(), prefix)
+	if !ok {
+		return ""
+	}
+	p.pos++
+	return v
+}
+
+/* ---------------- Field grammar ---------------- */
+
+func isScheme(s string) bool {
+	if s == "" || !isAlpha(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if !isAlpha(c) && !isDigit(c) && c != '+' && c != '-' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAuthority(s string) bool {
+	if s == "" || strings.ContainsAny(s, "/?# \t") {
+		return false
+	}
+	u, err := url.Parse("siwe://" + s)
+	return err == nil && u.Host == s
+}
+
+func isURI(s string) bool {
+	if s == "" || strings.ContainsAny(s, " \t\r\n") {
+		return false
+	}
+	u, err := url.Parse(s)
+	return err == nil && isScheme(u.Scheme)
+}
+
+func isChecksumAddress(s string) bool {
+	if len(s) != 42 || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	return ethgo.HexToAddress(s).String() == s
+}
+
+func isNonce(s string) bool {
+	if len(s) < 8 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isAlpha(s[i]) && !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlpha(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, s)
+}
@@ -0,0 +1,107 @@
+package siwe
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const testMessage = `service.org wants you to sign in with your Ethereum account:
+0x2c7536E3605D9C16a7a3D7b1898e529396a65c23
+
+I accept the ServiceOrg Terms of Service: https://service.org/tos
+
+URI: https://service.org/login
+Version: 1
+Chain ID: 1
+Nonce: 32891756
+Issued At: 2021-09-30T16:25:24Z
+Expiration Time: 2021-10-01T16:25:24Z
+Resources:
+- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/
+- https://example.com/my-web2-claim.json`
+
+func TestParseRoundTrip(t *testing.T) {
+	m, err := ParseMessage(testMessage)
+	require.NoError(t, err)
+	require.Equal(t, "service.org", m.Domain)
+	require.Equal(t, uint64(1), m.ChainID)
+	require.Equal(t, "32891756", m.Nonce)
+	require.Len(t, m.Resources, 2)
+	require.Equal(t, testMessage, m.String())
+
+	// no statement: two blank lines before URI
+	m.Statement = ""
+	m.Resources = nil
+	m2, err := ParseMessage(m.String())
+	require.NoError(t, err)
+	require.Equal(t, m.String(), m2.String())
+
+	// a bare "Resources:" line with no items
+	m.Resources = []string{}
+	bare := m.String()
+	require.True(t, strings.HasSuffix(bare, "\nResources:"))
+	m2, err = ParseMessage(bare)
+	require.NoError(t, err)
+	require.NotNil(t, m2.Resources)
+	require.Empty(t, m2.Resources)
+	require.Equal(t, bare, m2.String())
+}
+
+func TestParseStrict(t *testing.T) {
+	for _, bad := range []string{
+		"",
+		// lowercase address (not checksummed)
+		`service.org wants you to sign in with your Ethereum account:
+0x2c7536e3605d9c16a7a3d7b1898e529396a65c23
+
+
+URI: https://service.org/login
+Version: 1
+Chain ID: 1
+Nonce: 32891756
+Issued At: 2021-09-30T16:25:24Z`,
+		// short nonce
+		`service.org wants you to sign in with your Ethereum account:
+0x2c7536E3605D9C16a7a3D7b1898e529396a65c23
+
+
+URI: https://service.org/login
+Version: 1
+Chain ID: 1
+Nonce: 1234
+Issued At: 2021-09-30T16:25:24Z`,
+		// trailing garbage
+		testMessage + "\nfoo",
+	} {
+		_, err := ParseMessage(bad)
+		require.Error(t, err)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	m, err := ParseMessage(testMessage)
+	require.NoError(t, err)
+
+	sig, err := m.Sign(utils.NewRawPrivateSigner("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"))
+	require.NoError(t, err)
+
+	now := time.Date(2021, 9, 30, 18, 0, 0, 0, time.UTC)
+	require.NoError(t, m.Verify(sig, &VerifyOptions{Domain: "service.org", Nonce: "32891756", Time: now}))
+
+	var domainErr *DomainMismatchError
+	require.True(t, errors.As(m.Verify(sig, &VerifyOptions{Domain: "evil.org", Time: now}), &domainErr))
+
+	var nonceErr *NonceMismatchError
+	require.True(t, errors.As(m.Verify(sig, &VerifyOptions{Nonce: "aaaaaaaa", Time: now}), &nonceErr))
+
+	var expErr *ExpiredError
+	require.True(t, errors.As(m.Verify(sig, &VerifyOptions{Time: now.Add(48 * time.Hour)}), &expErr))
+
+	m.Nonce = "99999999"
+	require.ErrorIs(t, m.Verify(sig, &VerifyOptions{Time: now}), ErrInvalidSignature)
+}
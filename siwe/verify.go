@@ -0,0 +1,131 @@
+package siwe
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/gosunuts/ethtxbuilder/contract/erc1271"
+	"github.com/gosunuts/ethtxbuilder/utils"
+)
+
+var ErrInvalidSignature = errors.New("siwe: invalid signature")
+
+// ExpiredError is returned when the message is past its expiration time.
+type ExpiredError struct {
+	ExpirationTime time.Time
+	Now            time.Time
+}
+
+func (e *ExpiredError) Error() string {
+	return fmt.Sprintf("siwe: message expired at %s", e.ExpirationTime.Format(time.RFC3339))
+}
+
+// NotYetValidError is returned when the message is used before its not-before time.
+type NotYetValidError struct {
+	NotBefore time.Time
+	Now       time.Time
+}
+
+func (e *NotYetValidError) Error() string {
+	return fmt.Sprintf("siwe: message not valid before %s", e.NotBefore.Format(time.RFC3339))
+}
+
+// DomainMismatchError is returned when the message domain is not the expected one.
+type DomainMismatchError struct {
+	Expected string
+	Got      string
+}
+
+func (e *DomainMismatchError) Error() string {
+	return fmt.Sprintf("siwe: domain mismatch (want %q, got %q)", e.Expected, e.Got)
+}
+
+// NonceMismatchError is returned when the message nonce is not the expected one.
+type NonceMismatchError struct {
+	Expected string
+	Got      string
+}
+
+func (e *NonceMismatchError) Error() string {
+	return fmt.Sprintf("siwe: nonce mismatch (want %q, got %q)", e.Expected, e.Got)
+}
+
+// VerifyOptions controls Verify. Empty fields skip the corresponding check.
+type VerifyOptions struct {
+	Domain string
+	Nonce  string
+	Time   time.Time // zero -> time.Now()
+
+	// Client enables ERC-1271 / ERC-6492 verification for smart accounts.
+	Client *client.Client
+}
+
+// Hash returns the EIP-191 personal_sign hash of the rendered message.
+func (m *Message) Hash() []byte {
+	return utils.HashPersonalMessage([]byte(m.String()))
+}
+
+// Sign signs the rendered message with personal_sign semantics.
+func (m *Message) Sign(sign utils.SignFunc) ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return utils.SignPersonalMessage([]byte(m.String()), sign)
+}
+
+// Verify validates the message fields, time window, domain, nonce and signature.
+func (m *Message) Verify(sig []byte, opts *VerifyOptions) error {
+	if opts == nil {
+		opts = &VerifyOptions{}
+	}
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	if opts.Domain != "" && opts.Domain != m.Domain {
+		return &DomainMismatchError{Expected: opts.Domain, Got: m.Domain}
+	}
+	if opts.Nonce != "" && opts.Nonce != m.Nonce {
+		return &NonceMismatchError{Expected: opts.Nonce, Got: m.Nonce}
+	}
+
+	now := opts.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if m.ExpirationTime != "" {
+		exp, _ := parseTime(m.ExpirationTime)
+		if !now.Before(exp) {
+			return &ExpiredError{ExpirationTime: exp, Now: now}
+		}
+	}
+	if m.NotBefore != "" {
+		nbf, _ := parseTime(m.NotBefore)
+		if now.Before(nbf) {
+			return &NotYetValidError{NotBefore: nbf, Now: now}
+		}
+	}
+
+	hash := m.Hash()
+	if recovered, err := utils.RecoverAddress(hash, sig); err == nil && strings.EqualFold(recovered, m.Address) {
+		return nil
+	}
+	if opts.Client == nil {
+		return ErrInvalidSignature
+	}
+
+	v, err := erc1271.NewVerifier(opts.Client)
+	if err != nil {
+		return err
+	}
+	ok, err := v.IsValidSignature(m.Address, hash, sig)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
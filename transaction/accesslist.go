@@ -0,0 +1,68 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/umbracle/ethgo"
+)
+
+// AccessListGas reports what AttachAccessList measured.
+type AccessListGas struct {
+	Without  uint64 // estimate without an access list
+	With     uint64 // estimate with the node's list (0 if none was produced)
+	Attached bool
+}
+
+// AttachAccessList asks the node for tx's access list and sets tx.Accesses
+// only when the estimate with the list is lower than without it. On nodes
+// without eth_createAccessList tx is left as is and only Without is filled.
+// tx must not be signed yet; tx.Gas is not changed.
+func AttachAccessList(ctx context.Context, c *client.Client, from string, tx *DynamicTx) (*AccessListGas, error) {
+	if tx.R != nil || tx.S != nil {
+		return nil, errors.New("access list must be attached before signing")
+	}
+	msg := &client.CallMsg{From: ethgo.HexToAddress(from), Data: tx.Data, Value: tx.Value}
+	if tx.To != nil {
+		to := ethgo.BytesToAddress(tx.To)
+		msg.To = &to
+	}
+
+	out := &AccessListGas{}
+	var err error
+	if out.Without, err = c.EstimateGasContext(ctx, msg); err != nil {
+		return nil, err
+	}
+	res, err := c.CreateAccessListContext(ctx, msg, ethgo.Latest)
+	if errors.Is(err, client.ErrMethodNotSupported) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(res.AccessList) == 0 {
+		return out, nil
+	}
+	if out.With, err = c.EstimateGasWithAccessListContext(ctx, msg, res.AccessList); err != nil {
+		return nil, err
+	}
+	if out.With < out.Without {
+		tx.Accesses = FromClientAccessList(res.AccessList)
+		tx.rawtx = nil
+		out.Attached = true
+	}
+	return out, nil
+}
+
+// FromClientAccessList converts a node access list to the encoder's form.
+func FromClientAccessList(list []client.AccessTuple) AccessList {
+	out := make(AccessList, len(list))
+	for i, t := range list {
+		out[i].Address = t.Address.Bytes()
+		for _, k := range t.StorageKeys {
+			out[i].StorageKeys = append(out[i].StorageKeys, k.Bytes())
+		}
+	}
+	return out
+}
@@ -1,8 +1,11 @@
 package transaction
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 
+	"github.com/gosunuts/ethtxbuilder/client"
 	"github.com/gosunuts/ethtxbuilder/utils"
 	"github.com/umbracle/fastrlp"
 )
@@ -65,3 +68,45 @@ func EncodeBlob4844(tx *BlobTx) []byte {
 	enc := l.MarshalTo(nil)
 	return append([]byte{0x03}, enc...)
 }
+
+// BlobGas returns the blob gas the transaction consumes.
+func (tx *BlobTx) BlobGas() uint64 {
+	return uint64(len(tx.BlobVersionedHashes)) * client.BlobGasPerBlob
+}
+
+// BlobCost returns the blob fee burned at the given blob base fee.
+func (tx *BlobTx) BlobCost(blobBaseFee *big.Int) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(tx.BlobGas()), blobBaseFee)
+}
+
+// MaxBlobCost returns the most the blobs can cost (BlobGas * MaxFeePerBlobGas).
+func (tx *BlobTx) MaxBlobCost() *big.Int {
+	return tx.BlobCost(bigOrZero(tx.MaxFeePerBlobGas))
+}
+
+// MaxCost returns the balance the sender needs: gas and blob gas at their
+// fee caps plus value.
+func (tx *BlobTx) MaxCost() *big.Int {
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas), bigOrZero(tx.MaxFeePerGas))
+	cost.Add(cost, tx.MaxBlobCost())
+	return cost.Add(cost, bigOrZero(tx.Value))
+}
+
+// CheckBlobs checks the blob count against a fork's limits.
+func (tx *BlobTx) CheckBlobs(p client.BlobParams) error {
+	n := uint64(len(tx.BlobVersionedHashes))
+	if n == 0 {
+		return errors.New("blob tx has no blobs")
+	}
+	if limit := min(p.TxBlobLimit(), p.Max); n > limit {
+		return fmt.Errorf("blob tx has %d blobs, limit is %d", n, limit)
+	}
+	return nil
+}
+
+func bigOrZero(x *big.Int) *big.Int {
+	if x == nil {
+		return new(big.Int)
+	}
+	return x
+}
@@ -0,0 +1,172 @@
+package transaction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+const domainABI = `[
+  {"name":"eip712Domain","type":"function","stateMutability":"view","inputs":[],"outputs":[
+    {"name":"fields","type":"bytes1"},
+    {"name":"name","type":"string"},
+    {"name":"version","type":"string"},
+    {"name":"chainId","type":"uint256"},
+    {"name":"verifyingContract","type":"address"},
+    {"name":"salt","type":"bytes32"},
+    {"name":"extensions","type":"uint256[]"}]},
+  {"name":"name","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+  {"name":"version","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+  {"name":"DOMAIN_SEPARATOR","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"bytes32"}]}
+]`
+
+var domainRuntime = abi.MustNewABI(domainABI)
+
+// errNoMethod marks a domain read that reverted or returned no data, i.e.
+// the contract does not implement it.
+var errNoMethod = errors.New("method not implemented")
+
+// EIP-5267 fields bitmap.
+const (
+	domainFieldName byte = 1 << iota
+	domainFieldVersion
+	domainFieldChainID
+	domainFieldVerifyingContract
+	domainFieldSalt
+)
+
+// DiscoverDomain returns the EIP-712 domain of contract.
+//
+// It calls eip712Domain() (EIP-5267) first. When the contract does not
+// implement it (the call reverts or returns no data), candidate domains
+// built from name() and version() are compared against DOMAIN_SEPARATOR()
+// and the matching one is returned. Other errors are returned as is.
+func DiscoverDomain(c *client.Client, contract string) (Domain, error) {
+	d, err := domainFromEIP5267(c, contract)
+	if err == nil {
+		return d, nil
+	}
+	if !errors.Is(err, errNoMethod) {
+		return Domain{}, fmt.Errorf("eip712Domain: %w", err)
+	}
+	fd, ferr := domainFromSeparator(c, contract)
+	if ferr != nil {
+		return Domain{}, fmt.Errorf("eip712Domain: %v; fallback: %w", err, ferr)
+	}
+	return fd, nil
+}
+
+func domainFromEIP5267(c *client.Client, contract string) (Domain, error) {
+	out, err := callDomainMethod(c, contract, "eip712Domain")
+	if err != nil {
+		return Domain{}, err
+	}
+	fields, ok := out["fields"].([1]byte)
+	if !ok {
+		return Domain{}, errors.New("unexpected fields type")
+	}
+	if ext, _ := out["extensions"].([]*big.Int); len(ext) > 0 {
+		return Domain{}, fmt.Errorf("unsupported EIP-5267 extensions %v", ext)
+	}
+	// Domain omits empty strings, so an empty-but-present name/version
+	// would hash differently from the contract.
+	if (fields[0]&domainFieldName != 0 && out["name"] == "") ||
+		(fields[0]&domainFieldVersion != 0 && out["version"] == "") {
+		return Domain{}, errors.New("empty name/version in EIP-5267 domain is not supported")
+	}
+
+	var d Domain
+	if fields[0]&domainFieldName != 0 {
+		d.Name, _ = out["name"].(string)
+	}
+	if fields[0]&domainFieldVersion != 0 {
+		d.Version, _ = out["version"].(string)
+	}
+	if fields[0]&domainFieldChainID != 0 {
+		d.ChainID, _ = out["chainId"].(*big.Int)
+	}
+	if fields[0]&domainFieldVerifyingContract != 0 {
+		addr, _ := out["verifyingContract"].(ethgo.Address)
+		d.VerifyingContract = addr.String()
+	}
+	if fields[0]&domainFieldSalt != 0 {
+		salt, _ := out["salt"].([32]byte)
+		d.Salt = "0x" + hex.EncodeToString(salt[:])
+	}
+	return d, nil
+}
+
+func domainFromSeparator(c *client.Client, contract string) (Domain, error) {
+	out, err := callDomainMethod(c, contract, "DOMAIN_SEPARATOR")
+	if err != nil {
+		return Domain{}, err
+	}
+	onchain, ok := out["0"].([32]byte)
+	if !ok {
+		return Domain{}, errors.New("unexpected DOMAIN_SEPARATOR type")
+	}
+
+	var name string
+	out, err = callDomainMethod(c, contract, "name")
+	switch {
+	case err == nil:
+		name, _ = out["0"].(string)
+	case !errors.Is(err, errNoMethod):
+		return Domain{}, err
+	}
+	versions := []string{"1", "2", ""}
+	out, err = callDomainMethod(c, contract, "version")
+	switch {
+	case err == nil:
+		if v, _ := out["0"].(string); v != "" {
+			versions = append([]string{v}, versions...)
+		}
+	case !errors.Is(err, errNoMethod):
+		return Domain{}, err
+	}
+
+	addr := ethgo.HexToAddress(contract).String()
+	for _, v := range versions {
+		for _, chainID := range []*big.Int{c.ChainId, nil} {
+			d := Domain{Name: name, Version: v, ChainID: chainID, VerifyingContract: addr}
+			ds, err := (&TypedData{Types: Types{}, Domain: d}).DomainSeparator()
+			if err != nil {
+				continue
+			}
+			if bytes.Equal(ds, onchain[:]) {
+				return d, nil
+			}
+		}
+	}
+	return Domain{}, fmt.Errorf("no candidate domain matches DOMAIN_SEPARATOR %x", onchain)
+}
+
+func callDomainMethod(c *client.Client, contract, method string) (map[string]any, error) {
+	m := domainRuntime.Methods[method]
+	if m == nil {
+		return nil, errors.New("method not found: " + method)
+	}
+	to := ethgo.HexToAddress(contract)
+	outHex, err := c.Call(&client.CallMsg{To: &to, Data: m.ID()}, ethgo.Latest)
+	if errors.Is(err, client.ErrExecutionReverted) {
+		return nil, fmt.Errorf("%s: %w: %w", method, errNoMethod, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if outHex == "0x" || outHex == "" {
+		return nil, fmt.Errorf("%s: %w: empty return data", method, errNoMethod)
+	}
+	b, err := utils.HexToBytes(outHex)
+	if err != nil {
+		return nil, err
+	}
+	return m.Decode(b)
+}
@@ -0,0 +1,153 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+const domainContract = "0x00000000000000000000000000000000000C0FFe"
+
+// newDomainNode answers eth_calls by method name from results (hex outputs);
+// missing methods revert and results starting with {"code": are sent as
+// JSON-RPC errors.
+func newDomainNode(t *testing.T, results map[string]string) *client.Client {
+	bySelector := map[string]string{}
+	for name, out := range results {
+		bySelector[hex.EncodeToString(domainRuntime.Methods[name].ID())] = out
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req codec.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		res := codec.Response{ID: req.ID}
+		switch req.Method {
+		case "eth_chainId":
+			res.Result = json.RawMessage(`"0x1"`)
+		case "eth_call":
+			var params []json.RawMessage
+			require.NoError(t, json.Unmarshal(req.Params, &params))
+			var msg struct {
+				Data string `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(params[0], &msg))
+			out, ok := bySelector[strings.TrimPrefix(msg.Data, "0x")]
+			switch {
+			case ok && strings.HasPrefix(out, `{"code":`):
+				res.Error = &codec.ErrorObject{}
+				require.NoError(t, json.Unmarshal([]byte(out), res.Error))
+			case ok:
+				res.Result = json.RawMessage(`"` + out + `"`)
+			default:
+				res.Error = &codec.ErrorObject{Code: 3, Message: "execution reverted"}
+			}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	t.Cleanup(srv.Close)
+	c, err := client.NewClient(srv.URL)
+	require.NoError(t, err)
+	return c
+}
+
+func encodeOutputs(t *testing.T, method string, v any) string {
+	b, err := domainRuntime.Methods[method].Outputs.Encode(v)
+	require.NoError(t, err)
+	return "0x" + hex.EncodeToString(b)
+}
+
+func TestDiscoverDomainEIP5267(t *testing.T) {
+	salt := [32]byte{0xaa, 0xbb}
+	addr := ethgo.HexToAddress(domainContract)
+	for _, test := range []struct {
+		fields byte
+		want   Domain
+	}{
+		{0x0f, Domain{Name: "Token", Version: "1", ChainID: big.NewInt(1), VerifyingContract: addr.String()}},
+		{0x1f, Domain{Name: "Token", Version: "1", ChainID: big.NewInt(1), VerifyingContract: addr.String(), Salt: "0xaabb" + strings.Repeat("00", 30)}},
+		{0x0d, Domain{Name: "Token", ChainID: big.NewInt(1), VerifyingContract: addr.String()}},
+		{0x0c, Domain{ChainID: big.NewInt(1), VerifyingContract: addr.String()}},
+	} {
+		c := newDomainNode(t, map[string]string{
+			"eip712Domain": encodeOutputs(t, "eip712Domain", map[string]any{
+				"fields":            [1]byte{test.fields},
+				"name":              "Token",
+				"version":           "1",
+				"chainId":           big.NewInt(1),
+				"verifyingContract": addr,
+				"salt":              salt,
+				"extensions":        []*big.Int{},
+			}),
+		})
+		d, err := DiscoverDomain(c, domainContract)
+		require.NoError(t, err, "fields %#x", test.fields)
+		require.Equal(t, test.want, d, "fields %#x", test.fields)
+	}
+}
+
+func TestDiscoverDomainFallback(t *testing.T) {
+	addr := ethgo.HexToAddress(domainContract).String()
+	want := Domain{Name: "Token", Version: "2", ChainID: big.NewInt(1), VerifyingContract: addr}
+	ds, err := (&TypedData{Types: Types{}, Domain: want}).DomainSeparator()
+	require.NoError(t, err)
+
+	// eip712Domain() and version() revert; only the version "2" candidate
+	// with the chain id matches DOMAIN_SEPARATOR().
+	c := newDomainNode(t, map[string]string{
+		"name":             encodeOutputs(t, "name", []any{"Token"}),
+		"DOMAIN_SEPARATOR": "0x" + hex.EncodeToString(ds),
+	})
+	d, err := DiscoverDomain(c, domainContract)
+	require.NoError(t, err)
+	require.Equal(t, want, d)
+
+	c = newDomainNode(t, map[string]string{
+		"name":             encodeOutputs(t, "name", []any{"Other"}),
+		"DOMAIN_SEPARATOR": "0x" + hex.EncodeToString(ds),
+	})
+	_, err = DiscoverDomain(c, domainContract)
+	require.ErrorContains(t, err, "no candidate domain matches")
+}
+
+func TestDiscoverDomainErrors(t *testing.T) {
+	addr := ethgo.HexToAddress(domainContract).String()
+	want := Domain{Name: "Token", Version: "1", ChainID: big.NewInt(1), VerifyingContract: addr}
+	ds, err := (&TypedData{Types: Types{}, Domain: want}).DomainSeparator()
+	require.NoError(t, err)
+	limited := `{"code":-32005,"message":"request rate limited"}`
+
+	// A node error from eip712Domain() is returned, not guessed around.
+	c := newDomainNode(t, map[string]string{
+		"eip712Domain":     limited,
+		"name":             encodeOutputs(t, "name", []any{"Token"}),
+		"DOMAIN_SEPARATOR": "0x" + hex.EncodeToString(ds),
+	})
+	_, err = DiscoverDomain(c, domainContract)
+	require.ErrorIs(t, err, client.ErrRateLimited)
+
+	// So is one from the fallback reads.
+	c = newDomainNode(t, map[string]string{
+		"name":             limited,
+		"DOMAIN_SEPARATOR": "0x" + hex.EncodeToString(ds),
+	})
+	_, err = DiscoverDomain(c, domainContract)
+	require.ErrorIs(t, err, client.ErrRateLimited)
+
+	// Empty return data counts as a missing eip712Domain().
+	c = newDomainNode(t, map[string]string{
+		"eip712Domain":     "0x",
+		"name":             encodeOutputs(t, "name", []any{"Token"}),
+		"DOMAIN_SEPARATOR": "0x" + hex.EncodeToString(ds),
+	})
+	d, err := DiscoverDomain(c, domainContract)
+	require.NoError(t, err)
+	require.Equal(t, want, d)
+}
@@ -66,15 +66,24 @@ func baseType(t string) string {
 	}
 	return t
 }
+
+// rootType strips every array suffix: "Person[][]" -> "Person".
+func rootType(t string) string {
+	for strings.HasSuffix(t, "[]") {
+		t = baseType(t)
+	}
+	return t
+}
+
 func (td *TypedData) deps(primary string, seen map[string]bool, order *[]string) {
-	primary = baseType(primary)
+	primary = rootType(primary)
 	if seen[primary] || td.Types[primary] == nil {
 		return
 	}
 	seen[primary] = true
 	*order = append(*order, primary)
 	for _, f := range td.Types[primary] {
-		if isRefType(baseType(f.Type)) {
+		if isRefType(rootType(f.Type)) {
 			td.deps(f.Type, seen, order)
 		}
 	}
@@ -218,9 +227,64 @@ func (td *TypedData) HashTypedData() ([]byte, error) {
 	return utils.Keccak(buf.Bytes()), nil
 }
 
+// Sign signs the EIP-712 digest. The returned signature is r(32) || s(32) || v
+// where v is 27/28, as eth_signTypedData_v4 returns it.
+func (td *TypedData) Sign(sign utils.SignFunc) ([]byte, error) {
+	digest, err := td.HashTypedData()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sign(digest)
+	if err != nil {
+		return nil, err
+	}
+	v, r, s, err := utils.SplitSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(r[:], s[:]...), v), nil
+}
+
 /* ---------------- Array & primitive helpers ---------------- */
 
+// encodeArray hashes the concatenated element words; elements that are
+// arrays themselves (T[][]) contribute their own array hash.
 func (td *TypedData) encodeArray(elemType string, v any) ([]byte, error) {
+	s, err := toAnySlice(v)
+	if err != nil {
+		return nil, err
+	}
+	var cat bytes.Buffer
+	for _, it := range s {
+		if strings.HasSuffix(elemType, "[]") {
+			digest, err := td.encodeArray(baseType(elemType), it)
+			if err != nil {
+				return nil, err
+			}
+			cat.Write(digest)
+		} else if isRefType(elemType) {
+			obj, ok := it.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("array elem expects object")
+			}
+			enc, err := td.EncodeData(elemType, obj)
+			if err != nil {
+				return nil, err
+			}
+			cat.Write(utils.Keccak(enc))
+		} else {
+			w, err := encodePrimitive(elemType, it)
+			if err != nil {
+				return nil, err
+			}
+			cat.Write(w)
+		}
+	}
+	return utils.Keccak(cat.Bytes()), nil
+}
+
+// toAnySlice normalizes the slice shapes accepted for EIP-712 arrays.
+func toAnySlice(v any) ([]any, error) {
 	s, ok := v.([]any)
 	if !ok {
 		switch vv := v.(type) {
@@ -267,27 +331,7 @@ func (td *TypedData) encodeArray(elemType string, v any) ([]byte, error) {
 			return nil, fmt.Errorf("array expects slice")
 		}
 	}
-	var cat bytes.Buffer
-	for _, it := range s {
-		if isRefType(elemType) {
-			obj, ok := it.(map[string]any)
-			if !ok {
-				return nil, fmt.Errorf("array elem expects object")
-			}
-			enc, err := td.EncodeData(elemType, obj)
-			if err != nil {
-				return nil, err
-			}
-			cat.Write(utils.Keccak(enc))
-		} else {
-			w, err := encodePrimitive(elemType, it)
-			if err != nil {
-				return nil, err
-			}
-			cat.Write(w)
-		}
-	}
-	return utils.Keccak(cat.Bytes()), nil
+	return s, nil
 }
 
 /* --------- Primitive encoding to 32-byte word --------- */
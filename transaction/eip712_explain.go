@@ -0,0 +1,155 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+)
+
+// Explanation is a step-by-step trace of HashTypedData.
+type Explanation struct {
+	Domain  *StructTrace
+	Message *StructTrace
+	Digest  []byte // keccak256(0x19 0x01 || domainSeparator || hashStruct(message))
+}
+
+// StructTrace describes how one struct value was encoded.
+type StructTrace struct {
+	Type        string
+	EncodedType string // e.g. "Mail(Person from,Person to,string contents)Person(...)"
+	TypeHash    []byte
+	Fields      []*FieldTrace
+	Hash        []byte // keccak256(typeHash || words...)
+}
+
+// FieldTrace describes the 32-byte word emitted for one field.
+// Struct is set for nested structs, Elements for arrays.
+type FieldTrace struct {
+	Name     string
+	Type     string
+	Word     []byte
+	Struct   *StructTrace
+	Elements []*FieldTrace
+}
+
+/* ---------------- Explain ---------------- */
+
+// Explain traces the encoding of the domain and the message: type strings,
+// type hashes and every field's 32-byte word, with nested structs and arrays
+// expanded. Words line up with Solidity's abi.encode(typeHash, ...) inputs.
+func (td *TypedData) Explain() (*Explanation, error) {
+	td.ensureDomainType()
+	dom, err := td.traceStruct("EIP712Domain", td.Domain.Map())
+	if err != nil {
+		return nil, fmt.Errorf("EIP712Domain: %w", err)
+	}
+	msg, err := td.traceStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", td.PrimaryType, err)
+	}
+	pre := append([]byte{0x19, 0x01}, dom.Hash...)
+	pre = append(pre, msg.Hash...)
+	return &Explanation{Domain: dom, Message: msg, Digest: utils.Keccak(pre)}, nil
+}
+
+func (td *TypedData) traceStruct(primary string, data map[string]any) (*StructTrace, error) {
+	fields := td.Types[primary]
+	if fields == nil {
+		return nil, fmt.Errorf("unknown type %q", primary)
+	}
+	st := &StructTrace{
+		Type:        primary,
+		EncodedType: string(td.EncodeType(primary)),
+		TypeHash:    td.TypeHash(primary),
+	}
+	enc := append([]byte{}, st.TypeHash...)
+	for _, f := range fields {
+		ft, err := td.traceField(f.Name, f.Type, data[f.Name])
+		if err != nil {
+			return nil, err
+		}
+		st.Fields = append(st.Fields, ft)
+		enc = append(enc, ft.Word...)
+	}
+	st.Hash = utils.Keccak(enc)
+	return st, nil
+}
+
+func (td *TypedData) traceField(name, typ string, v any) (*FieldTrace, error) {
+	ft := &FieldTrace{Name: name, Type: typ}
+
+	if strings.HasSuffix(typ, "[]") {
+		elemType := baseType(typ)
+		items, err := toAnySlice(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		var cat []byte
+		for i, it := range items {
+			et, err := td.traceField(fmt.Sprintf("%s[%d]", name, i), elemType, it)
+			if err != nil {
+				return nil, err
+			}
+			ft.Elements = append(ft.Elements, et)
+			cat = append(cat, et.Word...)
+		}
+		ft.Word = utils.Keccak(cat)
+		return ft, nil
+	}
+
+	if isRefType(typ) {
+		sub, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s expects object", name)
+		}
+		st, err := td.traceStruct(typ, sub)
+		if err != nil {
+			return nil, err
+		}
+		ft.Struct, ft.Word = st, st.Hash
+		return ft, nil
+	}
+
+	w, err := encodePrimitive(typ, v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	ft.Word = w
+	return ft, nil
+}
+
+/* ---------------- Formatting ---------------- */
+
+// String renders the explanation as an indented, diff-friendly listing.
+func (e *Explanation) String() string {
+	var sb strings.Builder
+	sb.WriteString("domain:\n")
+	e.Domain.write(&sb, 1)
+	sb.WriteString("message:\n")
+	e.Message.write(&sb, 1)
+	sb.WriteString("digest: 0x" + hex.EncodeToString(e.Digest) + "\n")
+	return sb.String()
+}
+
+func (st *StructTrace) write(sb *strings.Builder, depth int) {
+	ind := strings.Repeat("  ", depth)
+	sb.WriteString(ind + "type:     " + st.EncodedType + "\n")
+	sb.WriteString(ind + "typeHash: 0x" + hex.EncodeToString(st.TypeHash) + "\n")
+	for _, f := range st.Fields {
+		f.write(sb, depth)
+	}
+	sb.WriteString(ind + "hash:     0x" + hex.EncodeToString(st.Hash) + "\n")
+}
+
+func (f *FieldTrace) write(sb *strings.Builder, depth int) {
+	ind := strings.Repeat("  ", depth)
+	sb.WriteString(fmt.Sprintf("%s%s %s: 0x%s\n", ind, f.Type, f.Name, hex.EncodeToString(f.Word)))
+	if f.Struct != nil {
+		f.Struct.write(sb, depth+1)
+	}
+	for _, el := range f.Elements {
+		el.write(sb, depth+1)
+	}
+}
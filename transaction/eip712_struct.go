@@ -0,0 +1,411 @@
+package transaction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/umbracle/ethgo"
+)
+
+// Struct tags: `eip712:"name,type"`. Both parts are optional; name defaults
+// to the lowerCamel field name and type is inferred from the Go type.
+// `eip712:"-"` skips the field. Nested struct types use the Go type name,
+// or the type given in the tag (e.g. `eip712:"from,Sender"`). Two different
+// Go types under one EIP-712 type name are rejected.
+//
+//	type Person struct {
+//		Name   string        `eip712:"name"`
+//		Wallet ethgo.Address `eip712:"wallet"`
+//	}
+//	type Mail struct {
+//		From     Person   `eip712:"from"`
+//		To       []Person `eip712:"to"`
+//		Contents string   `eip712:"contents"`
+//		Amount   *big.Int `eip712:"amount,uint128"`
+//	}
+const structTag = "eip712"
+
+var (
+	bigIntType  = reflect.TypeOf((*big.Int)(nil))
+	addressType = reflect.TypeOf(ethgo.Address{})
+)
+
+type structField struct {
+	index int
+	name  string
+	typ   string
+}
+
+// NewTypedDataFromStruct builds TypedData whose types and message are
+// derived from the tagged struct v (or pointer to struct).
+func NewTypedDataFromStruct(domain Domain, v any) (*TypedData, error) {
+	primary, types, msg, err := TypesFromStruct(v)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedData{Types: types, PrimaryType: primary, Domain: domain, Message: msg}, nil
+}
+
+// TypesFromStruct returns the primary type name, the type definitions of v
+// and every nested struct, and the message map for v.
+func TypesFromStruct(v any) (string, Types, map[string]any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return "", nil, nil, fmt.Errorf("eip712: expected struct, got %s", rv.Kind())
+	}
+	types := Types{}
+	if err := collectTypes(rv.Type(), rv.Type().Name(), types, map[string]reflect.Type{}); err != nil {
+		return "", nil, nil, err
+	}
+	msg, err := structToMessage(rv)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return rv.Type().Name(), types, msg, nil
+}
+
+// collectTypes adds t and the structs it references to types, t under
+// name. seen records the Go type behind each name.
+func collectTypes(t reflect.Type, name string, types Types, seen map[string]reflect.Type) error {
+	if name == "" || !unicode.IsUpper([]rune(name)[0]) {
+		return fmt.Errorf("eip712: struct type %q must be named and start with an upper-case letter", t.String())
+	}
+	if prev, done := seen[name]; done {
+		if prev != t {
+			return fmt.Errorf("eip712: type name %q is used by both %s and %s", name, prev, t)
+		}
+		return nil
+	}
+	seen[name] = t // before recursing (self-references)
+	fields, err := structFields(t)
+	if err != nil {
+		return err
+	}
+	out := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		out = append(out, Field{Name: f.name, Type: f.typ})
+		if st := structElem(t.Field(f.index).Type); st != nil {
+			base, _, _ := strings.Cut(f.typ, "[")
+			if err := collectTypes(st, base, types, seen); err != nil {
+				return err
+			}
+		}
+	}
+	types[name] = out
+	return nil
+}
+
+func structFields(t reflect.Type) ([]structField, error) {
+	var out []structField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get(structTag)
+		if tag == "-" {
+			continue
+		}
+		name, typ, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = lowerFirst(sf.Name)
+		}
+		if typ == "" {
+			inferred, err := inferType(sf.Type)
+			if err != nil {
+				return nil, fmt.Errorf("eip712: %s.%s: %w", t.Name(), sf.Name, err)
+			}
+			typ = inferred
+		}
+		out = append(out, structField{index: i, name: name, typ: typ})
+	}
+	return out, nil
+}
+
+// structElem returns the struct type behind t (through pointers and slices), or nil.
+func structElem(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		if t == bigIntType {
+			return nil
+		}
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct && t != addressType {
+		return t
+	}
+	return nil
+}
+
+func inferType(t reflect.Type) (string, error) {
+	switch {
+	case t == bigIntType:
+		return "uint256", nil
+	case t == addressType:
+		return "address", nil
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return inferType(t.Elem())
+	case reflect.String:
+		return "string", nil
+	case reflect.Bool:
+		return "bool", nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		return fmt.Sprintf("uint%d", t.Bits()), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return fmt.Sprintf("int%d", t.Bits()), nil
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Len() >= 1 && t.Len() <= 32 {
+			return fmt.Sprintf("bytes%d", t.Len()), nil
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes", nil
+		}
+		elem, err := inferType(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return elem + "[]", nil
+	case reflect.Struct:
+		return t.Name(), nil
+	}
+	return "", fmt.Errorf("cannot infer EIP-712 type for %s", t)
+}
+
+/* ---------------- Struct -> message ---------------- */
+
+func structToMessage(rv reflect.Value) (map[string]any, error) {
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	msg := make(map[string]any, len(fields))
+	for _, f := range fields {
+		v, err := valueToMessage(rv.Field(f.index))
+		if err != nil {
+			return nil, fmt.Errorf("eip712: %s: %w", f.name, err)
+		}
+		msg[f.name] = v
+	}
+	return msg, nil
+}
+
+func valueToMessage(v reflect.Value) (any, error) {
+	switch {
+	case v.Type() == bigIntType:
+		if v.IsNil() {
+			return big.NewInt(0), nil
+		}
+		return v.Interface().(*big.Int), nil
+	case v.Type() == addressType:
+		return v.Interface().(ethgo.Address).String(), nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, fmt.Errorf("nil %s", v.Type())
+		}
+		return valueToMessage(v.Elem())
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		return new(big.Int).SetUint64(v.Uint()), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return big.NewInt(v.Int()), nil
+	case reflect.Array:
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return b, nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			e, err := valueToMessage(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = e
+		}
+		return out, nil
+	case reflect.Struct:
+		return structToMessage(v)
+	}
+	return nil, fmt.Errorf("unsupported value %s", v.Type())
+}
+
+/* ---------------- Message -> struct ---------------- */
+
+// UnmarshalMessage decodes a JSON typed-data message (the "message" object of
+// eth_signTypedData_v4 input) into the tagged struct pointed to by out.
+func UnmarshalMessage(data []byte, out any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var msg map[string]any
+	if err := dec.Decode(&msg); err != nil {
+		return err
+	}
+	return DecodeMessage(msg, out)
+}
+
+// DecodeMessage fills the tagged struct pointed to by out from a message map.
+func DecodeMessage(msg map[string]any, out any) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("eip712: out must be a non-nil pointer to struct")
+	}
+	return messageToStruct(msg, rv.Elem())
+}
+
+func messageToStruct(msg map[string]any, rv reflect.Value) error {
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		raw, ok := msg[f.name]
+		if !ok {
+			return fmt.Errorf("eip712: missing field %q", f.name)
+		}
+		if err := setValue(rv.Field(f.index), raw); err != nil {
+			return fmt.Errorf("eip712: %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+func setValue(dst reflect.Value, raw any) error {
+	if n, ok := raw.(json.Number); ok {
+		raw = n.String()
+	}
+	switch {
+	case dst.Type() == bigIntType:
+		bi, err := utils.AnyToBig(raw)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(bi))
+		return nil
+	case dst.Type() == addressType:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("address expects string, got %T", raw)
+		}
+		b, err := utils.FromHex(s)
+		if err != nil || len(b) != 20 {
+			return fmt.Errorf("invalid address %q", s)
+		}
+		dst.Set(reflect.ValueOf(ethgo.BytesToAddress(b)))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Pointer:
+		p := reflect.New(dst.Type().Elem())
+		if err := setValue(p.Elem(), raw); err != nil {
+			return err
+		}
+		dst.Set(p)
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("string expects string, got %T", raw)
+		}
+		dst.SetString(s)
+	case reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("bool expects bool, got %T", raw)
+		}
+		dst.SetBool(b)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		bi, err := utils.AnyToBig(raw)
+		if err != nil {
+			return err
+		}
+		if bi.Sign() < 0 || bi.BitLen() > dst.Type().Bits() {
+			return fmt.Errorf("%s overflows %s", bi, dst.Type())
+		}
+		dst.SetUint(bi.Uint64())
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		bi, err := utils.AnyToBig(raw)
+		if err != nil {
+			return err
+		}
+		if !bi.IsInt64() || dst.OverflowInt(bi.Int64()) {
+			return fmt.Errorf("%s overflows %s", bi, dst.Type())
+		}
+		dst.SetInt(bi.Int64())
+	case reflect.Array:
+		b, err := hexBytes(raw)
+		if err != nil {
+			return err
+		}
+		if len(b) != dst.Len() {
+			return fmt.Errorf("want %d bytes, got %d", dst.Len(), len(b))
+		}
+		reflect.Copy(dst, reflect.ValueOf(b))
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := hexBytes(raw)
+			if err != nil {
+				return err
+			}
+			dst.SetBytes(b)
+			return nil
+		}
+		items, ok := raw.([]any)
+		if !ok {
+			return fmt.Errorf("array expects JSON array, got %T", raw)
+		}
+		s := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, it := range items {
+			if err := setValue(s.Index(i), it); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		dst.Set(s)
+	case reflect.Struct:
+		m, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("struct expects object, got %T", raw)
+		}
+		return messageToStruct(m, dst)
+	default:
+		return fmt.Errorf("unsupported field type %s", dst.Type())
+	}
+	return nil
+}
+
+func hexBytes(raw any) ([]byte, error) {
+	switch x := raw.(type) {
+	case []byte:
+		return x, nil
+	case string:
+		if !strings.HasPrefix(x, "0x") && !strings.HasPrefix(x, "0X") {
+			return nil, fmt.Errorf("bytes expects 0x-hex, got %q", x)
+		}
+		return hex.DecodeString(x[2:])
+	}
+	return nil, fmt.Errorf("bytes expects 0x-hex string, got %T", raw)
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
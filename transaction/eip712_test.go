@@ -0,0 +1,169 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/gosunuts/ethtxbuilder/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/umbracle/ethgo"
+)
+
+// mailTypedData is the example from the EIP-712 specification.
+func mailTypedData() *TypedData {
+	return &TypedData{
+		Types: Types{
+			"Person": {
+				{Name: "name", Type: "string"},
+				{Name: "wallet", Type: "address"},
+			},
+			"Mail": {
+				{Name: "from", Type: "Person"},
+				{Name: "to", Type: "Person"},
+				{Name: "contents", Type: "string"},
+			},
+		},
+		PrimaryType: "Mail",
+		Domain: Domain{
+			Name:              "Ether Mail",
+			Version:           "1",
+			ChainID:           big.NewInt(1),
+			VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+		},
+		Message: map[string]any{
+			"from":     map[string]any{"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+			"to":       map[string]any{"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+			"contents": "Hello, Bob!",
+		},
+	}
+}
+
+func TestHashTypedData(t *testing.T) {
+	td := mailTypedData()
+	require.Equal(t, "a0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2", hex.EncodeToString(td.TypeHash("Mail")))
+
+	ds, err := td.DomainSeparator()
+	require.NoError(t, err)
+	require.Equal(t, "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f", hex.EncodeToString(ds))
+
+	digest, err := td.HashTypedData()
+	require.NoError(t, err)
+	require.Equal(t, "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", hex.EncodeToString(digest))
+}
+
+func TestExplain(t *testing.T) {
+	td := mailTypedData()
+	ex, err := td.Explain()
+	require.NoError(t, err)
+
+	digest, err := td.HashTypedData()
+	require.NoError(t, err)
+	require.Equal(t, digest, ex.Digest)
+
+	require.Equal(t, "Mail(Person from,Person to,string contents)Person(string name,address wallet)", ex.Message.EncodedType)
+	require.Len(t, ex.Message.Fields, 3)
+	from := ex.Message.Fields[0]
+	require.NotNil(t, from.Struct)
+	require.Equal(t, from.Struct.Hash, from.Word)
+	require.Equal(t, "000000000000000000000000cd2a3d9f938e13cd947ec05abc7fe734df8dd826", hex.EncodeToString(from.Struct.Fields[1].Word))
+
+	td.Types["Mail"] = append(td.Types["Mail"], Field{Name: "tags", Type: "string[]"})
+	td.Message["tags"] = []string{"a", "b"}
+	ex, err = td.Explain()
+	require.NoError(t, err)
+	require.Len(t, ex.Message.Fields[3].Elements, 2)
+	require.Contains(t, ex.String(), "string[] tags: 0x")
+}
+
+func TestNestedArrays(t *testing.T) {
+	td := mailTypedData()
+	td.Types["Mail"] = append(td.Types["Mail"],
+		Field{Name: "grid", Type: "uint256[][]"},
+		Field{Name: "groups", Type: "Person[][]"})
+	bob := td.Message["to"].(map[string]any)
+	td.Message["grid"] = []any{[]*big.Int{big.NewInt(1), big.NewInt(2)}, []any{big.NewInt(3)}}
+	td.Message["groups"] = []any{[]any{bob}, []any{}}
+
+	require.Equal(t,
+		"Mail(Person from,Person to,string contents,uint256[][] grid,Person[][] groups)Person(string name,address wallet)",
+		string(td.EncodeType("Mail")))
+
+	digest, err := td.HashTypedData()
+	require.NoError(t, err)
+	ex, err := td.Explain()
+	require.NoError(t, err)
+	require.Equal(t, digest, ex.Digest)
+
+	// uint256[][] hashes each inner array, then the concatenated inner hashes.
+	word := func(n byte) []byte { return append(make([]byte, 31), n) }
+	row0 := utils.Keccak(append(word(1), word(2)...))
+	row1 := utils.Keccak(word(3))
+	grid := ex.Message.Fields[3]
+	require.Equal(t, utils.Keccak(append(row0, row1...)), grid.Word)
+	require.Equal(t, row0, grid.Elements[0].Word)
+
+	bobHash, err := td.HashStruct("Person", bob)
+	require.NoError(t, err)
+	require.Equal(t, utils.Keccak(append(utils.Keccak(bobHash), utils.Keccak(nil)...)), ex.Message.Fields[4].Word)
+}
+
+type Person struct {
+	Name   string        `eip712:"name"`
+	Wallet ethgo.Address `eip712:"wallet"`
+}
+
+type Mail struct {
+	From     Person `eip712:"from"`
+	To       Person `eip712:"to"`
+	Contents string `eip712:"contents"`
+}
+
+func TestTypedDataFromStruct(t *testing.T) {
+	want := mailTypedData()
+	m := Mail{
+		From:     Person{Name: "Cow", Wallet: ethgo.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")},
+		To:       Person{Name: "Bob", Wallet: ethgo.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")},
+		Contents: "Hello, Bob!",
+	}
+	td, err := NewTypedDataFromStruct(want.Domain, &m)
+	require.NoError(t, err)
+	require.Equal(t, want.Types, td.Types)
+
+	got, err := td.HashTypedData()
+	require.NoError(t, err)
+	exp, err := want.HashTypedData()
+	require.NoError(t, err)
+	require.Equal(t, exp, got)
+
+	var back Mail
+	require.NoError(t, UnmarshalMessage([]byte(`{
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}`), &back))
+	require.Equal(t, m, back)
+}
+
+func TestTypesFromStructNames(t *testing.T) {
+	// A tag type names the nested struct's EIP-712 type.
+	type Envelope struct {
+		Sender Person   `eip712:"sender,Sender"`
+		CC     []Person `eip712:"cc,Sender[]"`
+	}
+	_, types, _, err := TypesFromStruct(Envelope{})
+	require.NoError(t, err)
+	require.Equal(t, mailTypedData().Types["Person"], types["Sender"])
+	require.NotContains(t, types, "Person")
+
+	// Two Go types cannot share one EIP-712 type name.
+	type Person struct {
+		Nick string `eip712:"nick"`
+	}
+	type Clash struct {
+		Local Person `eip712:"local"`
+		Mail  Mail   `eip712:"mail"`
+	}
+	_, _, _, err = TypesFromStruct(Clash{})
+	require.ErrorContains(t, err, `type name "Person" is used by both`)
+}
@@ -0,0 +1,117 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gosunuts/ethtxbuilder/client"
+	"github.com/umbracle/ethgo"
+)
+
+// SimTx is an unsigned transaction and the account it is sent from.
+type SimTx struct {
+	From string
+	Tx   any // *LegacyTx, *DynamicTx, *BlobTx or *SetCodeTx
+}
+
+// ToSimCall converts a transaction struct into an eth_simulateV1 call.
+// Signatures are ignored; zero Gas is left for the node to fill.
+func ToSimCall(from string, tx any) (*client.SimCall, error) {
+	call := &client.SimCall{From: ethgo.HexToAddress(from)}
+	var (
+		nonce, gas uint64
+		to         []byte
+	)
+	switch t := tx.(type) {
+	case *LegacyTx:
+		nonce, gas, to = t.Nonce, t.Gas, t.To
+		call.GasPrice, call.Value, call.Data = t.GasPrice, t.Value, t.Data
+	case *DynamicTx:
+		nonce, gas, to = t.Nonce, t.Gas, t.To
+		call.MaxFeePerGas, call.MaxPriorityFeePerGas = t.MaxFeePerGas, t.MaxPriorityFeePerGas
+		call.Value, call.Data = t.Value, t.Data
+		call.AccessList = toClientAccessList(t.Accesses)
+	case *BlobTx:
+		nonce, gas, to = t.Nonce, t.Gas, t.To
+		call.MaxFeePerGas, call.MaxPriorityFeePerGas = t.MaxFeePerGas, t.MaxPriorityFeePerGas
+		call.MaxFeePerBlobGas = t.MaxFeePerBlobGas
+		call.Value, call.Data = t.Value, t.Data
+		call.AccessList = toClientAccessList(t.AccessList)
+		for _, h := range t.BlobVersionedHashes {
+			call.BlobVersionedHashes = append(call.BlobVersionedHashes, ethgo.BytesToHash(h))
+		}
+	case *SetCodeTx:
+		nonce, gas, to = t.Nonce, t.Gas, t.Destination
+		call.MaxFeePerGas, call.MaxPriorityFeePerGas = t.MaxFeePerGas, t.MaxPriorityFeePerGas
+		call.Value, call.Data = t.Value, t.Data
+		call.AccessList = toClientAccessList(t.AccessList)
+		for _, a := range t.AuthorizationList {
+			auth := client.SimAuthorization{ChainID: a.ChainID, Address: ethgo.BytesToAddress(a.Address), YParity: a.YParity, R: a.R, S: a.S}
+			if a.Nonce != nil {
+				auth.Nonce = a.Nonce.Uint64()
+			}
+			call.AuthorizationList = append(call.AuthorizationList, auth)
+		}
+	case nil:
+		return nil, errors.New("nil transaction")
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %T", tx)
+	}
+
+	call.Nonce = &nonce
+	if gas != 0 {
+		call.Gas = &gas
+	}
+	if to != nil {
+		addr := ethgo.BytesToAddress(to)
+		call.To = &addr
+	}
+	return call, nil
+}
+
+// NewSimBlock builds one simulated block from txs, applying state first.
+// state may be nil.
+func NewSimBlock(state client.StateOverride, txs ...SimTx) (*client.SimBlock, error) {
+	b := &client.SimBlock{StateOverrides: state}
+	for i, tx := range txs {
+		call, err := ToSimCall(tx.From, tx.Tx)
+		if err != nil {
+			return nil, fmt.Errorf("tx %d: %w", i, err)
+		}
+		b.Calls = append(b.Calls, call)
+	}
+	return b, nil
+}
+
+// Simulate runs txs in order in one block on top of the latest block and
+// returns a result per tx. opts may be nil.
+func Simulate(ctx context.Context, c *client.Client, opts *client.SimulateOptions, txs ...SimTx) ([]*client.SimCallResult, error) {
+	b, err := NewSimBlock(nil, txs...)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := c.SimulateV1Context(ctx, []*client.SimBlock{b}, ethgo.Latest, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) != 1 || len(blocks[0].Calls) != len(txs) {
+		return nil, errors.New("unexpected eth_simulateV1 result shape")
+	}
+	return blocks[0].Calls, nil
+}
+
+func toClientAccessList(al AccessList) []client.AccessTuple {
+	if al == nil {
+		return nil
+	}
+	out := make([]client.AccessTuple, len(al))
+	for i, t := range al {
+		out[i].Address = ethgo.BytesToAddress(t.Address)
+		out[i].StorageKeys = make([]ethgo.Hash, len(t.StorageKeys))
+		for j, k := range t.StorageKeys {
+			out[i].StorageKeys[j] = ethgo.BytesToHash(k)
+		}
+	}
+	return out
+}
@@ -1,6 +1,7 @@
 package transaction
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/gosunuts/ethtxbuilder/client"
@@ -8,25 +9,40 @@ import (
 )
 
 func BroadcastTx(client *client.Client, from string, to string, amount *big.Int, sign utils.SignFunc) (string, error) {
-	nonce, err := client.NonceManager.Next(from)
-	if err != nil {
-		return "", err
-	}
-	maxPriorityFeePerGas, err := client.SuggestGasTipCap()
+	return BroadcastTxContext(context.Background(), client, from, to, amount, sign)
+}
+
+// BroadcastTxContext is BroadcastTx with a context covering every RPC it makes.
+// Fees come from the client's FeeOracle (standard profile); chains without
+// EIP-1559 get an EIP-155 legacy transaction.
+func BroadcastTxContext(ctx context.Context, c *client.Client, from string, to string, amount *big.Int, sign utils.SignFunc) (string, error) {
+	nonce, err := c.NonceManager.NextContext(ctx, from)
 	if err != nil {
 		return "", err
 	}
-
-	maxFeePerGas, err := client.SuggestGasPrice()
+	fee, err := client.NewFeeOracle(c).Suggest(ctx, client.FeeStandard)
 	if err != nil {
 		return "", err
 	}
 
 	gasLimit := 21000
 
-	rawTx, err := NewTransferTx(client.ChainId, nonce, to, amount, uint64(gasLimit), maxPriorityFeePerGas, maxFeePerGas, nil, sign)
+	var rawTx []byte
+	if fee.Legacy {
+		tx := NewLegacyTx(nonce, to, amount, uint64(gasLimit), fee.GasPrice, nil)
+		tx.ChainID = c.ChainId
+		if err := tx.Sign(sign); err != nil {
+			return "", err
+		}
+		rawTx = tx.EncodeRLP()
+	} else {
+		rawTx, err = NewTransferTx(c.ChainId, nonce, to, amount, uint64(gasLimit), fee.TipCap, fee.FeeCap, nil, sign)
+		if err != nil {
+			return "", err
+		}
+	}
 
-	txhash, err := client.SendRawTransaction(rawTx)
+	txhash, err := c.SendRawTransactionContext(ctx, rawTx)
 	if err != nil {
 		return "", err
 	}
@@ -34,7 +50,7 @@ func BroadcastTx(client *client.Client, from string, to string, amount *big.Int,
 }
 
 func NewTransferTx(chainId *big.Int, nonce uint64, to string, amount *big.Int, gasLimit uint64, maxPriorityFeePerGas *big.Int, maxFeePerGas *big.Int, data []byte, sign utils.SignFunc) ([]byte, error) {
-	tx := NewDynamicTx(chainId, nonce, to, amount, gasLimit, maxFeePerGas, maxFeePerGas, nil)
+	tx := NewDynamicTx(chainId, nonce, to, amount, gasLimit, maxPriorityFeePerGas, maxFeePerGas, data)
 	err := tx.Sign(sign)
 	if err != nil {
 		return nil, err
@@ -1,8 +1,12 @@
 package transaction
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gosunuts/ethtxbuilder/client"
@@ -22,3 +26,88 @@ func TestBroadcastTx(t *testing.T) {
 	require.NoError(t, err)
 	fmt.Println(txid)
 }
+
+func TestBlobTxCost(t *testing.T) {
+	tx := &BlobTx{
+		Gas:                 21000,
+		MaxFeePerGas:        big.NewInt(10),
+		Value:               big.NewInt(5),
+		BlobVersionedHashes: make([][]byte, 2),
+		MaxFeePerBlobGas:    big.NewInt(3),
+	}
+	require.Equal(t, uint64(2*client.BlobGasPerBlob), tx.BlobGas())
+	require.Equal(t, int64(2*client.BlobGasPerBlob), tx.BlobCost(big.NewInt(1)).Int64())
+	require.Equal(t, int64(21000*10+2*client.BlobGasPerBlob*3+5), tx.MaxCost().Int64())
+
+	require.NoError(t, tx.CheckBlobs(client.BlobParams{Target: 3, Max: 6}))
+	tx.BlobVersionedHashes = make([][]byte, 7)
+	require.Error(t, tx.CheckBlobs(client.BlobParams{Target: 6, Max: 9, MaxPerTx: 6}))
+}
+
+func TestAttachAccessList(t *testing.T) {
+	var withList, withoutList, created string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		result := `"0x1"`
+		switch req.Method {
+		case "eth_estimateGas":
+			result = withoutList
+			if strings.Contains(string(req.Params[0]), "accessList") {
+				result = withList
+			}
+		case "eth_createAccessList":
+			result = created
+		}
+		if strings.HasPrefix(result, `{"code"`) {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":%s}`, req.ID, result)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, result)
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient(srv.URL)
+	require.NoError(t, err)
+	from := "0x0000000000000000000000000000000000000001"
+	slot := "0x" + strings.Repeat("00", 31) + "01"
+	created = `{"accessList":[{"address":"0x0000000000000000000000000000000000000002","storageKeys":["` + slot + `"]}],"gasUsed":"0x7530"}`
+
+	withoutList, withList = `"0x7918"`, `"0x7850"`
+	tx := NewDynamicTx(big.NewInt(1), 0, "0x0000000000000000000000000000000000000003", nil, 0, nil, nil, []byte{1})
+	res, err := AttachAccessList(t.Context(), c, from, tx)
+	require.NoError(t, err)
+	require.True(t, res.Attached)
+	require.Len(t, tx.Accesses, 1)
+	require.Len(t, tx.Accesses[0].StorageKeys, 1)
+
+	withList = `"0x7a00"`
+	tx = NewDynamicTx(big.NewInt(1), 0, "0x0000000000000000000000000000000000000003", nil, 0, nil, nil, []byte{1})
+	res, err = AttachAccessList(t.Context(), c, from, tx)
+	require.NoError(t, err)
+	require.False(t, res.Attached)
+	require.Empty(t, tx.Accesses)
+
+	created = `{"code":-32601,"message":"the method eth_createAccessList does not exist/is not available"}`
+	res, err = AttachAccessList(t.Context(), c, from, tx)
+	require.NoError(t, err)
+	require.False(t, res.Attached)
+	require.Equal(t, uint64(0x7918), res.Without)
+}
+
+func TestToSimCall(t *testing.T) {
+	tx := NewDynamicTx(big.NewInt(1), 4, "0x0000000000000000000000000000000000000002", big.NewInt(5), 0, big.NewInt(1), big.NewInt(2), []byte{0xaa})
+	call, err := ToSimCall("0x0000000000000000000000000000000000000001", tx)
+	require.NoError(t, err)
+	raw, err := json.Marshal(call)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"from":"0x0000000000000000000000000000000000000001","to":"0x0000000000000000000000000000000000000002",`+
+		`"nonce":"0x4","value":"0x5","maxFeePerGas":"0x2","maxPriorityFeePerGas":"0x1","input":"0xaa"}`, string(raw))
+
+	_, err = ToSimCall("0x0000000000000000000000000000000000000001", struct{}{})
+	require.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// EIP-191 version bytes.
+const (
+	EIP191VersionIntendedValidator byte = 0x00
+	EIP191VersionStructuredData    byte = 0x01 // EIP-712
+	EIP191VersionPersonalSign      byte = 0x45 // 'E'
+)
+
+const personalMessagePrefix = "\x19Ethereum Signed Message:\n"
+
+// HashPersonalMessage returns keccak256("\x19Ethereum Signed Message:\n" || len(msg) || msg).
+func HashPersonalMessage(msg []byte) []byte {
+	buf := make([]byte, 0, len(personalMessagePrefix)+20+len(msg))
+	buf = append(buf, personalMessagePrefix...)
+	buf = strconv.AppendInt(buf, int64(len(msg)), 10)
+	buf = append(buf, msg...)
+	return Keccak(buf)
+}
+
+// HashIntendedValidatorMessage returns keccak256(0x19 || 0x00 || validator || msg).
+func HashIntendedValidatorMessage(validator string, msg []byte) ([]byte, error) {
+	addr, err := FromHex(validator)
+	if err != nil {
+		return nil, err
+	}
+	if len(addr) != 20 {
+		return nil, fmt.Errorf("validator length must be 20, got %d", len(addr))
+	}
+	buf := make([]byte, 0, 2+20+len(msg))
+	buf = append(buf, 0x19, EIP191VersionIntendedValidator)
+	buf = append(buf, addr...)
+	buf = append(buf, msg...)
+	return Keccak(buf), nil
+}
+
+// SignPersonalMessage signs msg as personal_sign does.
+// The returned signature is r(32) || s(32) || v where v is 27/28.
+func SignPersonalMessage(msg []byte, sign SignFunc) ([]byte, error) {
+	return signHashV27(HashPersonalMessage(msg), sign)
+}
+
+// SignIntendedValidatorMessage signs an EIP-191 version 0x00 message.
+// The returned signature is r(32) || s(32) || v where v is 27/28.
+func SignIntendedValidatorMessage(validator string, msg []byte, sign SignFunc) ([]byte, error) {
+	h, err := HashIntendedValidatorMessage(validator, msg)
+	if err != nil {
+		return nil, err
+	}
+	return signHashV27(h, sign)
+}
+
+// RecoverPersonalMessage returns the address that signed msg with personal_sign.
+// V may be 0/1 or 27/28.
+func RecoverPersonalMessage(msg, sig []byte) (string, error) {
+	return RecoverAddress(HashPersonalMessage(msg), sig)
+}
+
+// RecoverIntendedValidatorMessage returns the address that signed an EIP-191
+// version 0x00 message. V may be 0/1 or 27/28.
+func RecoverIntendedValidatorMessage(validator string, msg, sig []byte) (string, error) {
+	h, err := HashIntendedValidatorMessage(validator, msg)
+	if err != nil {
+		return "", err
+	}
+	return RecoverAddress(h, sig)
+}
+
+// RecoverAddress returns the signer address of a 65-byte signature over hash.
+// V may be 0/1 or 27/28.
+func RecoverAddress(hash, sig []byte) (string, error) {
+	norm, err := NormalizeSigV(sig)
+	if err != nil {
+		return "", err
+	}
+	pub, err := Ecrecover(hash, norm)
+	if err != nil {
+		return "", err
+	}
+	return RawAddrToStr(PubkeyToAddr(pub)), nil
+}
+
+// NormalizeSigV returns a copy of sig with V converted to 0/1.
+func NormalizeSigV(sig []byte) ([]byte, error) {
+	if len(sig) != SignatureLength {
+		return nil, ErrBadSignature
+	}
+	out := append([]byte{}, sig...)
+	if out[RecoveryIDIndex] >= VHomesteadOffset {
+		out[RecoveryIDIndex] -= VHomesteadOffset
+	}
+	if out[RecoveryIDIndex] > VParityMax {
+		return nil, ErrBadSignature
+	}
+	return out, nil
+}
+
+func signHashV27(hash []byte, sign SignFunc) ([]byte, error) {
+	sig, err := sign(hash)
+	if err != nil {
+		return nil, err
+	}
+	norm, err := NormalizeSigV(sig)
+	if err != nil {
+		return nil, err
+	}
+	norm[RecoveryIDIndex] += VHomesteadOffset
+	return norm, nil
+}
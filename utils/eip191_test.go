@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testPrivKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+func TestPersonalMessage(t *testing.T) {
+	h := HashPersonalMessage([]byte("hello"))
+	require.Equal(t, "50b2c43fd39106bafbba0da34fc430e1f91e3c96ea2acee2bc34119f92b37750", hex.EncodeToString(h))
+
+	sign := NewRawPrivateSigner(testPrivKey)
+	sig, err := SignPersonalMessage([]byte("hello"), sign)
+	require.NoError(t, err)
+	require.Contains(t, []byte{27, 28}, sig[RecoveryIDIndex])
+
+	addr, err := RecoverPersonalMessage([]byte("hello"), sig)
+	require.NoError(t, err)
+	require.Equal(t, "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23", addr)
+
+	// 0/1 parity recovers the same address
+	norm, err := NormalizeSigV(sig)
+	require.NoError(t, err)
+	addr2, err := RecoverPersonalMessage([]byte("hello"), norm)
+	require.NoError(t, err)
+	require.Equal(t, addr, addr2)
+}
+
+func TestIntendedValidatorMessage(t *testing.T) {
+	validator := "0x000000000000000000000000000000000000dEaD"
+	sign := NewRawPrivateSigner(testPrivKey)
+
+	sig, err := SignIntendedValidatorMessage(validator, []byte("data"), sign)
+	require.NoError(t, err)
+
+	addr, err := RecoverIntendedValidatorMessage(validator, []byte("data"), sig)
+	require.NoError(t, err)
+	require.Equal(t, "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23", addr)
+
+	other, err := RecoverIntendedValidatorMessage("0x0000000000000000000000000000000000000001", []byte("data"), sig)
+	require.NoError(t, err)
+	require.NotEqual(t, addr, other)
+
+	_, err = HashIntendedValidatorMessage("0x1234", nil)
+	require.Error(t, err)
+}
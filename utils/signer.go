@@ -189,3 +189,15 @@ func V27ToParity(V *big.Int) (byte, error) {
 	}
 	return byte(val - VHomesteadOffset), nil
 }
+
+// SplitSignature splits a 65-byte signature into v (27/28), r and s.
+// V may be 0/1 or 27/28.
+func SplitSignature(sig []byte) (v uint8, r, s [32]byte, err error) {
+	norm, err := NormalizeSigV(sig)
+	if err != nil {
+		return 0, r, s, err
+	}
+	copy(r[:], norm[:32])
+	copy(s[:], norm[32:64])
+	return norm[RecoveryIDIndex] + VHomesteadOffset, r, s, nil
+}